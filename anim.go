@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// animFPS is how many frames a second generated animations are streamed at.
+const animFPS = 20
+
+func doAnimCommand(client Client, colors *ini.Section, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf anim " + strings.Join(generatorNames(), "|") + " [--speed <speed>] [--palette <color>,...]")
+	}
+
+	if len(args) < 1 {
+		return usage()
+	}
+	newGenerator, ok := generators[args[0]]
+	if !ok {
+		return usage()
+	}
+
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	speed := fs.Float64("speed", 1, "Speed relative to normal")
+	paletteNames := fs.String("palette", "", "Comma-separated colors to use instead of the defaults")
+	if err := parseFlags(fs, args[1:]); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 || *speed <= 0 {
+		return usage()
+	}
+
+	var palette []RGB
+	if *paletteNames != "" {
+		for _, name := range strings.Split(*paletteNames, ",") {
+			c, err := parseColor(name, colors)
+			if err != nil {
+				return err
+			}
+			palette = append(palette, c)
+		}
+	}
+
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	polygons := layoutPolygons(panelInfo)
+	if len(polygons) == 0 {
+		return errors.New("no panels in layout")
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	generator := newGenerator(generatorPanels(polygons), palette, rng)
+
+	interval := time.Second / animFPS
+	frame := 0
+	return streamFrames(client, func() ([]SetPanelColor, time.Duration, bool, error) {
+		t := float64(frame) * interval.Seconds() * *speed
+		frame++
+
+		colors := generator.Frame(t)
+		for id, c := range colors {
+			colors[id] = client.calibrated(c)
+		}
+		return panelColorFrame(colors, interval), interval, true, nil
+	})
+}
+
+// generatorNames returns the names of the available generators.
+func generatorNames() []string {
+	var names []string
+	for name := range generators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
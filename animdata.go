@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PanelFrame is a single keyframe of a panel in custom effect animation data.
+type PanelFrame struct {
+	Red            uint8
+	Green          uint8
+	Blue           uint8
+	White          uint8
+	TransitionTime uint16
+}
+
+// PanelAnimation is the sequence of keyframes for one panel.
+type PanelAnimation struct {
+	PanelID uint16
+	Frames  []PanelFrame
+}
+
+// ParseAnimData parses the `animData` string of a static or custom effect:
+//
+//	numPanels (panelId numFrames (R G B W T){numFrames}){numPanels}
+func ParseAnimData(data string) ([]PanelAnimation, error) {
+	fields := strings.Fields(data)
+	pos := 0
+	next := func(bits int) (uint64, error) {
+		if pos >= len(fields) {
+			return 0, fmt.Errorf("animData ended early")
+		}
+		v, err := strconv.ParseUint(fields[pos], 10, bits)
+		if err != nil {
+			return 0, fmt.Errorf("invalid animData value %q", fields[pos])
+		}
+		pos++
+		return v, nil
+	}
+
+	numPanels, err := next(16)
+	if err != nil {
+		return nil, err
+	}
+
+	panels := make([]PanelAnimation, numPanels)
+	for i := range panels {
+		panelID, err := next(16)
+		if err != nil {
+			return nil, err
+		}
+		numFrames, err := next(16)
+		if err != nil {
+			return nil, err
+		}
+
+		panels[i].PanelID = uint16(panelID)
+		panels[i].Frames = make([]PanelFrame, numFrames)
+		for j := range panels[i].Frames {
+			var values [5]uint64
+			for k := range values {
+				bits := 8
+				if k == 4 {
+					bits = 16
+				}
+				values[k], err = next(bits)
+				if err != nil {
+					return nil, err
+				}
+			}
+			panels[i].Frames[j] = PanelFrame{
+				Red:            uint8(values[0]),
+				Green:          uint8(values[1]),
+				Blue:           uint8(values[2]),
+				White:          uint8(values[3]),
+				TransitionTime: uint16(values[4]),
+			}
+		}
+	}
+
+	if pos != len(fields) {
+		return nil, fmt.Errorf("unexpected data after %d panels", numPanels)
+	}
+	return panels, nil
+}
+
+// EncodeAnimData encodes panel animations as an `animData` string.
+func EncodeAnimData(panels []PanelAnimation) string {
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(len(panels)))
+	for _, panel := range panels {
+		fmt.Fprintf(&b, " %d %d", panel.PanelID, len(panel.Frames))
+		for _, f := range panel.Frames {
+			fmt.Fprintf(&b, " %d %d %d %d %d", f.Red, f.Green, f.Blue, f.White, f.TransitionTime)
+		}
+	}
+	return b.String()
+}
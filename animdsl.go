@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// ParseAnimationSource parses an animation written in picoleaf's animation
+// format, which describes custom effect animation data one frame per line:
+//
+//	# Alternate two panels between red and blue.
+//	loop
+//
+//	panel 12
+//	frame red 1s
+//	frame blue 1s
+//
+//	panel 34,56
+//	frame blue 1s
+//	frame #ff0000 500ms
+//
+// Each frame gives a color and the time to transition to it. Frames belong
+// to the most recent `panel` line, which may list several panels. The
+// animation loops if it contains a `loop` line. Lines starting with `#` are
+// comments.
+func ParseAnimationSource(r io.Reader, colors *ini.Section) ([]PanelAnimation, bool, error) {
+	var panels []PanelAnimation
+	var current []int
+	loop := false
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		fail := func(format string, args ...interface{}) ([]PanelAnimation, bool, error) {
+			return nil, false, validationErrorf("line %d: %s", line, fmt.Sprintf(format, args...))
+		}
+
+		switch fields[0] {
+		case "loop":
+			if len(fields) != 1 {
+				return fail("expected `loop`")
+			}
+			loop = true
+		case "panel":
+			if len(fields) != 2 {
+				return fail("expected `panel <id>[,<id>...]`")
+			}
+			current = nil
+			for _, field := range strings.Split(fields[1], ",") {
+				id, err := strconv.ParseUint(field, 10, 16)
+				if err != nil {
+					return fail("expected panel ID between 0-%d, got %s", math.MaxUint16, field)
+				}
+				current = append(current, len(panels))
+				panels = append(panels, PanelAnimation{PanelID: uint16(id)})
+			}
+		case "frame":
+			if len(fields) != 3 {
+				return fail("expected `frame <color> <transition>`")
+			}
+			if current == nil {
+				return fail("frame before any panel")
+			}
+
+			c, err := parseColor(fields[1], colors)
+			if err != nil {
+				return fail("%v", err)
+			}
+
+			transition, err := time.ParseDuration(fields[2])
+			if err != nil || transition < 0 {
+				return fail("expected a transition time like 500ms or 2s, got %s", fields[2])
+			}
+			// Transition times are in tenths of a second.
+			tenths := math.Round(transition.Seconds() * 10)
+			if tenths > math.MaxUint16 {
+				return fail("transition time %s is too long", fields[2])
+			}
+
+			frame := PanelFrame{Red: c.Red, Green: c.Green, Blue: c.Blue, TransitionTime: uint16(tenths)}
+			for _, i := range current {
+				panels[i].Frames = append(panels[i].Frames, frame)
+			}
+		default:
+			return fail("unknown keyword %q", fields[0])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, false, err
+	}
+
+	for _, panel := range panels {
+		if len(panel.Frames) == 0 {
+			return nil, false, validationErrorf("panel %d has no frames", panel.PanelID)
+		}
+	}
+	if len(panels) == 0 {
+		return nil, false, validationErrorf("animation has no panels")
+	}
+	return panels, loop, nil
+}
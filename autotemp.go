@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// defaultAutotempTransition is how long autotemp takes to fade from one
+// scheduled level to the next, unless configured otherwise.
+const defaultAutotempTransition = 30 * time.Minute
+
+// tempSchedulePoint is a light level that takes effect at a time of day.
+type tempSchedulePoint struct {
+	At    time.Duration // since midnight
+	Level lightLevel
+}
+
+// parseTempSchedule parses a comma-separated schedule of time, color
+// temperature and brightness, like "07:00 5000K 100%, 21:00 2700K 40%".
+// The result is sorted by time of day.
+func parseTempSchedule(entries []string) ([]tempSchedulePoint, error) {
+	var points []tempSchedulePoint
+	for _, entry := range entries {
+		fields := strings.Fields(entry)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("expected \"HH:MM <kelvin>K <brightness>%%\", got %q", entry)
+		}
+
+		at, err := time.Parse("15:04", fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("expected time as HH:MM, got %s", fields[0])
+		}
+		kelvin, err := strconv.Atoi(strings.TrimSuffix(strings.ToUpper(fields[1]), "K"))
+		if err != nil {
+			return nil, fmt.Errorf("expected color temperature like 2700K, got %s", fields[1])
+		}
+		brightness, err := strconv.Atoi(strings.TrimSuffix(fields[2], "%"))
+		if err != nil || brightness < 0 || brightness > 100 {
+			return nil, fmt.Errorf("expected brightness between 0-100%%, got %s", fields[2])
+		}
+
+		points = append(points, tempSchedulePoint{
+			At:    time.Duration(at.Hour())*time.Hour + time.Duration(at.Minute())*time.Minute,
+			Level: lightLevel{kelvin, brightness},
+		})
+	}
+
+	sort.Slice(points, func(i, j int) bool { return points[i].At < points[j].At })
+	return points, nil
+}
+
+// tempScheduleLevel returns the scheduled light level at t. Each point's
+// level fades in from the previous one over transition.
+func tempScheduleLevel(points []tempSchedulePoint, t time.Time, transition time.Duration) lightLevel {
+	now := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second
+
+	// Before the first point of the day, yesterday's last point applies.
+	current := len(points) - 1
+	for i, p := range points {
+		if p.At <= now {
+			current = i
+		}
+	}
+	previous := (current + len(points) - 1) % len(points)
+
+	elapsed := now - points[current].At
+	if elapsed < 0 {
+		elapsed += 24 * time.Hour
+	}
+	if elapsed >= transition {
+		return points[current].Level
+	}
+	f := float64(elapsed) / float64(transition)
+	return points[previous].Level.lerp(points[current].Level, f)
+}
+
+func doAutotempCommand(client Client, settings *ini.Section, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf autotemp [--transition <duration>] [--every <duration>] [--once]")
+	}
+
+	fs := flag.NewFlagSet("autotemp", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	transition := fs.Duration("transition", settings.Key("transition").MustDuration(defaultAutotempTransition), "How long to fade between scheduled levels")
+	every := fs.Duration("every", time.Minute, "How often to adjust")
+	once := fs.Bool("once", false, "Adjust once and exit")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 || *transition < 0 || *every <= 0 {
+		return usage()
+	}
+
+	entries := settings.Key("schedule").Strings(",")
+	if len(entries) == 0 {
+		return errors.New("no schedule configured; add one to the [autotemp] section of your config file")
+	}
+	points, err := parseTempSchedule(entries)
+	if err != nil {
+		return fmt.Errorf("invalid schedule: %w", err)
+	}
+
+	last := lightLevel{-1, -1}
+	for {
+		level := tempScheduleLevel(points, time.Now(), *transition)
+		if client.Verbose {
+			fmt.Printf("%dK, %d%%\n", level.Kelvin, level.Brightness)
+		}
+
+		last = setLightLevel(client, level, last)
+
+		if *once {
+			return nil
+		}
+		time.Sleep(*every)
+	}
+}
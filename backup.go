@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+// Backup is everything about a Nanoleaf that can be restored to it, or to
+// another controller. Effects are kept as the device sent them, so they're
+// restored exactly, including fields Effect doesn't model.
+type Backup struct {
+	Time            time.Time         `json:"time"`
+	Model           string            `json:"model"`
+	SerialNo        string            `json:"serialNo"`
+	FirmwareVersion string            `json:"firmwareVersion"`
+	Orientation     int               `json:"orientation"`
+	State           Snapshot          `json:"state"`
+	Effects         []json.RawMessage `json:"effects"`
+}
+
+func doBackupCommand(client Client, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf backup [-o <file>]")
+	}
+
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	output := fs.String("o", "", "File to write the backup to, instead of standard output")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return usage()
+	}
+
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	snapshot, err := client.Snapshot()
+	if err != nil {
+		return fmt.Errorf("failed to get Nanoleaf state: %w", err)
+	}
+	effects, err := client.RequestAllEffectsData()
+	if err != nil {
+		return fmt.Errorf("failed to request effects: %w", err)
+	}
+
+	backup := Backup{
+		Time:            time.Now(),
+		Model:           panelInfo.Model,
+		SerialNo:        panelInfo.SerialNo,
+		FirmwareVersion: panelInfo.FirmwareVersion,
+		Orientation:     panelInfo.PanelLayout.GlobalOrientation.Value,
+		State:           *snapshot,
+		Effects:         effects,
+	}
+	data, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode backup: %w", err)
+	}
+
+	if *output == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+	err = ioutil.WriteFile(*output, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// doBackupRestoreCommand restores a backup's effects, orientation and
+// state. Effects that fail to restore are reported, and the rest carry on.
+func doBackupRestoreCommand(client Client, path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var backup Backup
+	err = json.Unmarshal(data, &backup)
+	if err != nil {
+		return fmt.Errorf("failed to parse backup: %w", err)
+	}
+
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	if backup.Model != "" && backup.Model != panelInfo.Model {
+		fmt.Printf("warning: backup is from a %s, restoring to a %s\n", backup.Model, panelInfo.Model)
+	}
+
+	failed := false
+	for _, data := range backup.Effects {
+		var effect Effect
+		err := json.Unmarshal(data, &effect)
+		if err == nil {
+			err = effect.Validate()
+		}
+		if err == nil {
+			// Sent as is, bypassing safety limits, since the effect was
+			// already on a device.
+			err = client.AddEffectData(data)
+		}
+		if err != nil {
+			fmt.Printf("error: failed to restore effect %s: %v\n", effect.Name, err)
+			failed = true
+		}
+	}
+
+	err = client.SetOrientation(backup.Orientation)
+	if err != nil {
+		return fmt.Errorf("failed to restore orientation: %w", err)
+	}
+
+	err = client.ApplySnapshot(backup.State)
+	if err != nil {
+		return fmt.Errorf("failed to restore state: %w", err)
+	}
+
+	if failed {
+		return errors.New("some effects failed to restore")
+	}
+	return nil
+}
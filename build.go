@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+)
+
+// Build-time settings, for packaging. Set them with the linker, e.g.:
+//
+//	go build -ldflags "-X main.buildMode=appliance"
+var (
+	// buildMode is "desktop" for per-user installs, or "appliance" for
+	// dedicated devices like a Raspberry Pi, which use system-wide paths.
+	buildMode = "desktop"
+
+	// buildConfigPath, if set, replaces the default config file path.
+	buildConfigPath = ""
+
+	// buildCacheDir, if set, replaces the default cache directory.
+	buildCacheDir = ""
+
+	// buildStateDir, if set, replaces the default directory for data
+	// picoleaf manages itself, like schedules and state history.
+	buildStateDir = ""
+)
+
+// Paths used in appliance mode. Only the config file is under /etc, since
+// everything else is written at runtime.
+const (
+	applianceConfigPath = "/etc/picoleafrc"
+	applianceCacheDir   = "/var/cache/picoleaf"
+	applianceStateDir   = "/var/lib/picoleaf"
+)
+
+// configPath returns the path of the config file.
+func configPath() (string, error) {
+	if buildConfigPath != "" {
+		return buildConfigPath, nil
+	}
+	if buildMode == "appliance" {
+		return applianceConfigPath, nil
+	}
+
+	usr, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(usr.HomeDir, defaultConfigFile), nil
+}
+
+// cacheDir returns the directory for picoleaf's cached data.
+func cacheDir() (string, error) {
+	if buildCacheDir != "" {
+		return buildCacheDir, nil
+	}
+	if buildMode == "appliance" {
+		return applianceCacheDir, nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "picoleaf"), nil
+}
+
+// stateDir returns the directory for data picoleaf manages itself, as
+// opposed to the hand-edited config file.
+func stateDir() (string, error) {
+	if buildStateDir != "" {
+		return buildStateDir, nil
+	}
+	if buildMode == "appliance" {
+		return applianceStateDir, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "picoleaf"), nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResponseCache holds GET responses so repeated requests can be answered
+// locally while fresh, or revalidated with conditional requests when the
+// device supplies an ETag or Last-Modified validator. Any other request
+// clears it, since writes change what the device would return.
+type ResponseCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// cacheEntry is a cached response body with its validators.
+type cacheEntry struct {
+	Body         string
+	ETag         string
+	LastModified string
+	Expires      time.Time
+}
+
+// NewResponseCache returns an empty ResponseCache.
+func NewResponseCache() *ResponseCache {
+	return &ResponseCache{entries: make(map[string]cacheEntry)}
+}
+
+// lookup returns the cached response for path.
+func (rc *ResponseCache) lookup(path string) (cacheEntry, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry, ok := rc.entries[path]
+	return entry, ok
+}
+
+// store caches a response according to its cache headers.
+func (rc *ResponseCache) store(path string, header http.Header, body string) {
+	entry := cacheEntry{
+		Body:         body,
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+	}
+
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		directive = strings.TrimSpace(strings.ToLower(directive))
+		switch {
+		case directive == "no-store":
+			rc.remove(path)
+			return
+		case strings.HasPrefix(directive, "max-age="):
+			seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age="))
+			if err == nil && seconds > 0 {
+				entry.Expires = time.Now().Add(time.Duration(seconds) * time.Second)
+			}
+		}
+	}
+
+	if entry.ETag == "" && entry.LastModified == "" && entry.Expires.IsZero() {
+		rc.remove(path)
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.entries[path] = entry
+}
+
+// remove drops the cached response for path.
+func (rc *ResponseCache) remove(path string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	delete(rc.entries, path)
+}
+
+// Clear drops all cached responses.
+func (rc *ResponseCache) Clear() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.entries = make(map[string]cacheEntry)
+}
+
+// fresh reports whether a cached response may be used without asking the
+// device.
+func (e cacheEntry) fresh() bool {
+	return time.Now().Before(e.Expires)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// calibrationStep is the amount each adjustment changes a gain or gamma.
+const calibrationStep = 0.05
+
+// calibrationColors are the test colors shown during calibration.
+var calibrationColors = []struct {
+	Name  string
+	Color RGB
+}{
+	{"white", RGB{255, 255, 255}},
+	{"gray", RGB{128, 128, 128}},
+	{"red", RGB{255, 0, 0}},
+	{"green", RGB{0, 255, 0}},
+	{"blue", RGB{0, 0, 255}},
+	{"orange", RGB{255, 165, 0}},
+}
+
+func doCalibrateCommand(client Client, cfg *ini.File, section *ini.Section, configFilePath string, args []string) error {
+	if len(args) != 0 {
+		return usageError("usage: picoleaf calibrate")
+	}
+
+	cal := DefaultCalibration
+	if client.Calibration != nil {
+		cal = *client.Calibration
+	}
+
+	fmt.Println("Adjust the panels until they match the swatch on screen.")
+	fmt.Println()
+	fmt.Println("  r+ r-  g+ g-  b+ b-    Raise or lower a channel gain")
+	fmt.Println("  gamma+ gamma-         Raise or lower gamma")
+	fmt.Println("  n                     Next test color")
+	fmt.Println("  s                     Save and quit")
+	fmt.Println("  q                     Quit without saving")
+	fmt.Println()
+
+	scanner := bufio.NewScanner(os.Stdin)
+	i := 0
+	for {
+		test := calibrationColors[i]
+		client.Calibration = &cal
+		err := client.SetRGB(int(test.Color.Red), int(test.Color.Green), int(test.Color.Blue))
+		if err != nil {
+			return fmt.Errorf("failed to set color: %w", err)
+		}
+
+		fmt.Printf("%s %-6s  gamma %.2f  red %.2f  green %.2f  blue %.2f\n", ansiSwatch(test.Color), test.Name, cal.Gamma, cal.RedGain, cal.GreenGain, cal.BlueGain)
+		fmt.Print("> ")
+		if !scanner.Scan() {
+			fmt.Println()
+			return nil
+		}
+
+		for _, cmd := range strings.Fields(scanner.Text()) {
+			switch cmd {
+			case "r+":
+				cal.RedGain += calibrationStep
+			case "r-":
+				cal.RedGain -= calibrationStep
+			case "g+":
+				cal.GreenGain += calibrationStep
+			case "g-":
+				cal.GreenGain -= calibrationStep
+			case "b+":
+				cal.BlueGain += calibrationStep
+			case "b-":
+				cal.BlueGain -= calibrationStep
+			case "gamma+":
+				cal.Gamma += calibrationStep
+			case "gamma-":
+				cal.Gamma -= calibrationStep
+			case "n":
+				i = (i + 1) % len(calibrationColors)
+			case "s":
+				return saveCalibration(cfg, section, configFilePath, cal)
+			case "q":
+				return nil
+			default:
+				fmt.Println("unknown command:", cmd)
+			}
+		}
+	}
+}
+
+// saveCalibration writes a calibration to a device's config section.
+func saveCalibration(cfg *ini.File, section *ini.Section, configFilePath string, cal Calibration) error {
+	section.Key("gamma").SetValue(fmt.Sprintf("%.2f", cal.Gamma))
+	section.Key("red_gain").SetValue(fmt.Sprintf("%.2f", cal.RedGain))
+	section.Key("green_gain").SetValue(fmt.Sprintf("%.2f", cal.GreenGain))
+	section.Key("blue_gain").SetValue(fmt.Sprintf("%.2f", cal.BlueGain))
+
+	err := cfg.SaveTo(configFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
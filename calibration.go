@@ -0,0 +1,31 @@
+package main
+
+import "math"
+
+// Calibration corrects colors for a particular device before they are
+// converted for the API.
+type Calibration struct {
+	Gamma     float64
+	RedGain   float64
+	GreenGain float64
+	BlueGain  float64
+}
+
+// DefaultCalibration leaves colors unchanged.
+var DefaultCalibration = Calibration{
+	Gamma:     1,
+	RedGain:   1,
+	GreenGain: 1,
+	BlueGain:  1,
+}
+
+// Apply gamma-corrects each channel, then scales it by its gain.
+func (cal Calibration) Apply(red, green, blue int) (int, int, int) {
+	return cal.channel(red, cal.RedGain), cal.channel(green, cal.GreenGain), cal.channel(blue, cal.BlueGain)
+}
+
+func (cal Calibration) channel(value int, gain float64) int {
+	v := math.Pow(float64(value)/255.0, cal.Gamma) * gain
+	v = math.Max(0, math.Min(1, v))
+	return int(math.Round(255 * v))
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// The circadian curve runs from its night values when the sun is at or
+// below circadianLowElevation (civil twilight) to its day values when the
+// sun is at or above circadianHighElevation, in degrees.
+const (
+	circadianLowElevation  = -6.0
+	circadianHighElevation = 30.0
+)
+
+func doCircadianCommand(client Client, settings *ini.Section, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf circadian --lat <latitude> --lon <longitude> [--min-kelvin <K>] [--max-kelvin <K>]\n                          [--min-brightness <0-100>] [--max-brightness <0-100>] [--every <duration>] [--once]")
+	}
+
+	fs := flag.NewFlagSet("circadian", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	lat := fs.Float64("lat", settings.Key("lat").MustFloat64(math.NaN()), "Latitude in degrees north")
+	lon := fs.Float64("lon", settings.Key("lon").MustFloat64(math.NaN()), "Longitude in degrees east")
+	minKelvin := fs.Int("min-kelvin", settings.Key("min_kelvin").MustInt(2700), "Color temperature at night")
+	maxKelvin := fs.Int("max-kelvin", settings.Key("max_kelvin").MustInt(6500), "Color temperature at midday")
+	minBrightness := fs.Int("min-brightness", settings.Key("min_brightness").MustInt(20), "Brightness at night")
+	maxBrightness := fs.Int("max-brightness", settings.Key("max_brightness").MustInt(100), "Brightness at midday")
+	every := fs.Duration("every", time.Minute, "How often to adjust")
+	once := fs.Bool("once", false, "Adjust once and exit")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 || math.IsNaN(*lat) || math.IsNaN(*lon) || *every <= 0 {
+		return usage()
+	}
+	if *minBrightness < 0 || *maxBrightness > 100 || *minBrightness > *maxBrightness {
+		return errors.New("brightness must be an integer 0-100, with the minimum below the maximum")
+	}
+
+	night := lightLevel{*minKelvin, *minBrightness}
+	day := lightLevel{*maxKelvin, *maxBrightness}
+	last := lightLevel{-1, -1}
+	for {
+		elevation := sunElevation(time.Now(), *lat, *lon)
+		f := (elevation - circadianLowElevation) / (circadianHighElevation - circadianLowElevation)
+		f = math.Max(0, math.Min(1, f))
+		// Ease in and out, so changes are gentle around dawn and dusk.
+		f = f * f * (3 - 2*f)
+
+		level := night.lerp(day, f)
+		if client.Verbose {
+			fmt.Printf("sun %.1f°: %dK, %d%%\n", elevation, level.Kelvin, level.Brightness)
+		}
+
+		last = setLightLevel(client, level, last)
+
+		if *once {
+			return nil
+		}
+		time.Sleep(*every)
+	}
+}
+
+// lightLevel is a color temperature and brightness.
+type lightLevel struct {
+	Kelvin     int
+	Brightness int
+}
+
+// lerp interpolates linearly between l and to, where f is between 0 and 1.
+func (l lightLevel) lerp(to lightLevel, f float64) lightLevel {
+	return lightLevel{
+		Kelvin:     l.Kelvin + int(math.Round(f*float64(to.Kelvin-l.Kelvin))),
+		Brightness: l.Brightness + int(math.Round(f*float64(to.Brightness-l.Brightness))),
+	}
+}
+
+// setLightLevel sets whichever of the color temperature and brightness
+// differ from last, and returns the level now set. Failures are reported
+// but not fatal, so long-running commands retry on their next update.
+func setLightLevel(client Client, level, last lightLevel) lightLevel {
+	if level.Kelvin != last.Kelvin {
+		err := client.SetColorTemperature(level.Kelvin)
+		if err != nil {
+			fmt.Println("error: failed to set color temperature:", err)
+		} else {
+			last.Kelvin = level.Kelvin
+		}
+	}
+	if level.Brightness != last.Brightness {
+		err := client.SetBrightness(level.Brightness)
+		if err != nil {
+			fmt.Println("error: failed to set brightness:", err)
+		} else {
+			last.Brightness = level.Brightness
+		}
+	}
+	return last
+}
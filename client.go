@@ -2,23 +2,84 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math"
 	"net"
 	"net/http"
+	"os"
+	"regexp"
+	"time"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ExternalControlPort is the UDP port for Nanoleaf external control.
 const ExternalControlPort = 60222
 
+// External control (UDP streaming) protocol versions.
+const (
+	StreamV1 = "v1"
+	StreamV2 = "v2"
+)
+
+// modelLightPanels is the model number of the original Light Panels (Aurora).
+const modelLightPanels = "NL22"
+
 // Client is a Nanoleaf REST API client.
 type Client struct {
 	Host  string
 	Token string
 
+	// Duration is the transition time for state changes. Brightness
+	// transitions are performed by the device; hue, saturation and color
+	// temperature are interpolated client-side.
+	Duration time.Duration
+
+	// StreamVersion forces the external control protocol version. When
+	// empty, the version is chosen based on the panel model.
+	StreamVersion string
+
+	// LegacyHSL makes SetRGB convert through HSL instead of HSV. Nanoleaf
+	// colors are HSV, so this only exists for compatibility with colors
+	// tuned against earlier versions.
+	LegacyHSL bool
+
+	// Calibration, if set, is applied to colors passed to SetRGB.
+	Calibration *Calibration
+
+	// Safety limits how quickly animations may change brightness. When
+	// nil, DefaultSafetyLimits are used.
+	Safety *SafetyLimits
+
+	// Cache, if set, caches GET responses according to their cache headers.
+	Cache *ResponseCache
+
+	// Trace, if set, records every API request.
+	Trace *Trace
+
+	// Transcript, if set, records every API request and response with
+	// their headers. Responses served from the cache aren't recorded.
+	Transcript *Transcript
+
+	// Queue, if set, saves state changes that fail because the device is
+	// unreachable, to be sent once it's back. Queued changes return an
+	// error matching ErrQueued, and other requests fail as usual.
+	Queue *RequestQueue
+
+	// History, if set, records the device's state before the first state
+	// change made through the client, so it can be undone.
+	History *HistoryRecorder
+
+	// Context, if set, is the parent of the OpenTelemetry spans for API
+	// requests, so they join the trace of whatever made them.
+	Context context.Context
+
 	Verbose bool
 
 	client http.Client
@@ -26,69 +87,121 @@ type Client struct {
 
 // Get performs a GET request.
 func (c Client) Get(path string) (string, error) {
-	if c.Verbose {
-		fmt.Println("GET", path)
-	}
-
-	url := c.Endpoint(path)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		return "", err
-	}
-
-	req.Header.Set("Accept", "application/json")
+	return c.Do(http.MethodGet, path, nil)
+}
 
-	res, err := c.client.Do(req)
-	if err != nil {
-		return "", err
-	}
+// Put performs a PUT request.
+func (c Client) Put(path string, body []byte) (string, error) {
+	return c.Do(http.MethodPut, path, body)
+}
 
-	if res.Body != nil {
-		defer res.Body.Close()
+// Do performs a request with an optional JSON body.
+func (c Client) Do(method string, path string, body []byte) (string, error) {
+	if c.History != nil && changesState(method, path, body) {
+		c.History.record(c)
+	}
+
+	_, span := startSpan(c.Context, method+" /"+path,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPMethodKey.String(method),
+			semconv.HTTPURLKey.String(redact(c.Endpoint(path))),
+		),
+	)
+	start := time.Now()
+	status, responseBody, err := c.do(method, path, body)
+	if status != 0 {
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+	}
+	endSpan(span, err)
+
+	if c.Trace != nil {
+		entry := TraceEntry{
+			Time:         start,
+			Method:       method,
+			URL:          redact(c.Endpoint(path)),
+			RequestBody:  string(body),
+			Status:       status,
+			ResponseBody: responseBody,
+			Duration:     time.Since(start).Seconds() * 1000,
+		}
+		if err != nil {
+			entry.Error = redact(err.Error())
+		}
+		if traceErr := c.Trace.Record(entry); traceErr != nil {
+			fmt.Fprintln(os.Stderr, "warning: failed to write trace:", traceErr)
+		}
 	}
 
-	body, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return "", err
+	if status == 0 && errors.Is(err, ErrNetwork) && c.queueRequest(method, path, body) {
+		return "", &QueuedError{Method: method, Path: path}
 	}
+	return responseBody, err
+}
 
+// do performs a request, returning the response status code and body.
+func (c Client) do(method string, path string, body []byte) (int, string, error) {
 	if c.Verbose {
-		fmt.Println("<===", string(body))
-		fmt.Println()
+		fmt.Println(method, path)
+		if body != nil {
+			fmt.Println("===>", string(body))
+		}
 	}
-	return string(body), nil
-}
 
-// Put performs a PUT request.
-func (c Client) Put(path string, body []byte) (string, error) {
-	if c.Verbose {
-		fmt.Println("PUT", path)
-		fmt.Println("===>", string(body))
+	var cached cacheEntry
+	var hasCached bool
+	if c.Cache != nil {
+		if method == http.MethodGet {
+			cached, hasCached = c.Cache.lookup(path)
+		} else {
+			c.Cache.Clear()
+		}
+	}
+	if hasCached && cached.fresh() {
+		if c.Verbose {
+			fmt.Println("<=== (cached)")
+			fmt.Println()
+		}
+		return http.StatusOK, cached.Body, nil
 	}
 
 	url := c.Endpoint(path)
-	req, err := http.NewRequest(http.MethodPut, url, nil)
+	req, err := http.NewRequest(method, url, nil)
 	if err != nil {
-		return "", err
+		return 0, "", err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
+	if hasCached {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
 
-	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	req.Header.Set("Accept", "application/json")
 
+	start := time.Now()
 	res, err := c.client.Do(req)
 	if err != nil {
-		return "", err
+		c.recordExchange(req, body, nil, nil, start, time.Time{}, err)
+		return 0, "", &NetworkError{Op: method + " " + path, Err: err}
 	}
 
 	if res.Body != nil {
 		defer res.Body.Close()
 	}
 
+	headersAt := time.Now()
 	responseBody, err := ioutil.ReadAll(res.Body)
+	c.recordExchange(req, body, res, responseBody, start, headersAt, err)
 	if err != nil {
-		return "", err
+		return res.StatusCode, "", &NetworkError{Op: method + " " + path, Err: err}
 	}
 
 	if c.Verbose {
@@ -98,7 +211,25 @@ func (c Client) Put(path string, body []byte) (string, error) {
 		}
 		fmt.Println()
 	}
-	return string(responseBody), nil
+
+	if res.StatusCode == http.StatusNotModified && hasCached {
+		c.Cache.store(path, res.Header, cached.Body)
+		return res.StatusCode, cached.Body, nil
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, string(responseBody), &APIError{
+			Method:     method,
+			Path:       path,
+			StatusCode: res.StatusCode,
+			Body:       string(responseBody),
+		}
+	}
+
+	if c.Cache != nil && method == http.MethodGet {
+		c.Cache.store(path, res.Header, string(responseBody))
+	}
+	return res.StatusCode, string(responseBody), nil
 }
 
 // Endpoint returns the full URL for an API endpoint.
@@ -106,6 +237,15 @@ func (c Client) Endpoint(path string) string {
 	return fmt.Sprintf("http://%s/api/v1/%s/%s", c.Host, c.Token, path)
 }
 
+// apiTokenPattern matches the access token in API URLs.
+var apiTokenPattern = regexp.MustCompile(`/api/v1/[^/\s"]+/`)
+
+// redact hides access tokens in any API URLs appearing in s, such as in
+// error messages, for logging and for showing to other programs.
+func redact(s string) string {
+	return apiTokenPattern.ReplaceAllString(s, "/api/v1/REDACTED/")
+}
+
 // Effects represents the Nanoleaf panel effects state.
 type Effects struct {
 	Selected string   `json:"select"`
@@ -128,18 +268,21 @@ type Rhythm struct {
 	} `json:"rhythmPos"`
 }
 
+// PanelPosition represents the position and orientation of a single panel.
+type PanelPosition struct {
+	PanelID   int `json:"panelId"`
+	X         int `json:"x"`
+	Y         int `json:"y"`
+	O         int `json:"o"`
+	ShapeType int `json:"shapeType"`
+}
+
 // PanelLayout represents the Nanoleaf panel layout.
 type PanelLayout struct {
 	Layout struct {
-		NumPanels    int `json:"numPanels"`
-		SideLength   int `json:"sideLength"`
-		PositionData []struct {
-			PanelID   int `json:"panelId"`
-			X         int `json:"x"`
-			Y         int `json:"y"`
-			O         int `json:"o"`
-			ShapeType int `json:"shapeType"`
-		} `json:"positionData"`
+		NumPanels    int             `json:"numPanels"`
+		SideLength   int             `json:"sideLength"`
+		PositionData []PanelPosition `json:"positionData"`
 	} `json:"layout"`
 	GlobalOrientation struct {
 		Value int `json:"value"`
@@ -173,6 +316,12 @@ func (c Client) GetPanelInfo() (*PanelInfo, error) {
 	return &panelInfo, err
 }
 
+// Identify makes Nanoleaf flash its panels.
+func (c Client) Identify() error {
+	_, err := c.Put("identify", nil)
+	return err
+}
+
 // ListEffects returns an array of effect names.
 func (c Client) ListEffects() ([]string, error) {
 	body, err := c.Get("effects/effectsList")
@@ -187,6 +336,10 @@ func (c Client) ListEffects() ([]string, error) {
 
 // Off turns off Nanoleaf.
 func (c Client) Off() error {
+	if c.Duration > 0 {
+		return c.fadeOff()
+	}
+
 	state := State{
 		On: &OnProperty{false},
 	}
@@ -200,6 +353,10 @@ func (c Client) Off() error {
 
 // On turns on Nanoleaf.
 func (c Client) On() error {
+	if c.Duration > 0 {
+		return c.fadeOn()
+	}
+
 	state := State{
 		On: &OnProperty{true},
 	}
@@ -221,14 +378,14 @@ func (c Client) SelectEffect(name string) error {
 		return err
 	}
 
-	c.Put("effects/select", bytes)
-	return nil
+	_, err = c.Put("effects/select", bytes)
+	return err
 }
 
 // SetBrightness sets the Nanoleaf's brightness.
 func (c Client) SetBrightness(brightness int) error {
 	state := State{
-		Brightness: &BrightnessProperty{Value: brightness},
+		Brightness: &BrightnessProperty{Value: brightness, Duration: c.durationSeconds()},
 	}
 
 	bytes, err := json.Marshal(state)
@@ -236,12 +393,16 @@ func (c Client) SetBrightness(brightness int) error {
 		return err
 	}
 
-	c.Put("state", bytes)
-	return nil
+	_, err = c.Put("state", bytes)
+	return err
 }
 
 // SetColorTemperature sets the Nanoleaf's color temperature.
 func (c Client) SetColorTemperature(temperature int) error {
+	if c.Duration > 0 {
+		return c.fadeColorTemperature(temperature)
+	}
+
 	state := State{
 		ColorTemperature: &ColorTemperatureProperty{Value: temperature},
 	}
@@ -251,14 +412,55 @@ func (c Client) SetColorTemperature(temperature int) error {
 		return err
 	}
 
-	c.Put("state", bytes)
-	return nil
+	_, err = c.Put("state", bytes)
+	return err
+}
+
+// SetOrientation sets the global orientation of the panel layout, in
+// degrees.
+func (c Client) SetOrientation(degrees int) error {
+	req := panelLayoutRequest{}
+	req.GlobalOrientation.Value = degrees
+	bytes, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Put("panelLayout", bytes)
+	return err
+}
+
+// SetHue sets the Nanoleaf's hue.
+func (c Client) SetHue(hue int) error {
+	if c.Duration > 0 {
+		return c.fadeHue(hue)
+	}
+	return c.putState(State{Hue: &HueProperty{Value: hue}})
+}
+
+// SetSaturation sets the Nanoleaf's saturation.
+func (c Client) SetSaturation(sat int) error {
+	if c.Duration > 0 {
+		return c.fadeSaturation(sat)
+	}
+	return c.putState(State{Saturation: &SaturationProperty{Value: sat}})
 }
 
 // SetHSL sets the Nanoleaf's hue, saturation, and lightness (brightness).
+// The values are sent as-is, so the device interprets them as HSV.
 func (c Client) SetHSL(hue int, sat int, lightness int) error {
+	return c.SetHSV(hue, sat, lightness)
+}
+
+// SetHSV sets the Nanoleaf's hue, saturation, and value (brightness), the
+// device's native color model.
+func (c Client) SetHSV(hue int, sat int, value int) error {
+	if c.Duration > 0 {
+		return c.fadeHSV(hue, sat, value)
+	}
+
 	state := State{
-		Brightness: &BrightnessProperty{Value: lightness},
+		Brightness: &BrightnessProperty{Value: value},
 		Hue:        &HueProperty{Value: hue},
 		Saturation: &SaturationProperty{Value: sat},
 	}
@@ -268,20 +470,108 @@ func (c Client) SetHSL(hue int, sat int, lightness int) error {
 		return err
 	}
 
-	c.Put("state", bytes)
-	return nil
+	_, err = c.Put("state", bytes)
+	return err
 }
 
-// SetRGB sets the Nanoleaf's color by converting RGB to HSL.
+// SetRGB sets the Nanoleaf's color by converting RGB to HSV, or to HSL when
+// LegacyHSL is set.
 func (c Client) SetRGB(red int, green int, blue int) error {
-	h, s, l := rgbToHSL(red, green, blue)
-	return c.SetHSL(h, s, l)
+	return c.SetState(c.rgbState(red, green, blue))
 }
 
-// startExternalControl sets Nanoleaf to accept UDP input.
-func (c Client) startExternalControl() error {
-	_, err := c.Put("effects", []byte(`{"write":{"command":"display","animType":"extControl","extControlVersion":"v2"}}`))
-	return err
+// rgbState returns the state that displays an RGB color, calibrated and
+// converted to the client's color model.
+func (c Client) rgbState(red int, green int, blue int) State {
+	if c.Calibration != nil {
+		red, green, blue = c.Calibration.Apply(red, green, blue)
+	}
+
+	var h, s, v int
+	if c.LegacyHSL {
+		h, s, v = rgbToHSL(red, green, blue)
+	} else {
+		h, s, v = rgbToHSV(red, green, blue)
+	}
+	return State{
+		Brightness: &BrightnessProperty{Value: v},
+		Hue:        &HueProperty{Value: h},
+		Saturation: &SaturationProperty{Value: s},
+	}
+}
+
+// safetyLimits returns the client's safety limits, tightened to no looser
+// than the defaults.
+func (c Client) safetyLimits() SafetyLimits {
+	if c.Safety == nil {
+		return DefaultSafetyLimits
+	}
+	return c.Safety.Tighten()
+}
+
+// calibrated applies the client's calibration, if any, to a color.
+func (c Client) calibrated(color RGB) RGB {
+	if c.Calibration == nil {
+		return color
+	}
+	r, g, b := c.Calibration.Apply(int(color.Red), int(color.Green), int(color.Blue))
+	return RGB{uint8(r), uint8(g), uint8(b)}
+}
+
+// startExternalControl sets Nanoleaf to accept UDP input and returns the
+// address to stream to.
+func (c Client) startExternalControl(version string) (*net.UDPAddr, error) {
+	switch version {
+	case StreamV1:
+		body, err := c.Put("effects", []byte(`{"write":{"command":"display","animType":"extControl"}}`))
+		if err != nil {
+			return nil, err
+		}
+
+		var res externalControlResponse
+		err = json.Unmarshal([]byte(body), &res)
+		if err != nil {
+			return nil, err
+		}
+		return net.ResolveUDPAddr("udp", fmt.Sprintf("%s:%d", res.IPAddr, res.Port))
+	case StreamV2:
+		_, err := c.Put("effects", []byte(`{"write":{"command":"display","animType":"extControl","extControlVersion":"v2"}}`))
+		if err != nil {
+			return nil, err
+		}
+
+		hostAddr, err := net.ResolveTCPAddr("tcp", c.Host)
+		if err != nil {
+			return nil, err
+		}
+		return net.ResolveUDPAddr("udp", fmt.Sprintf("%s:%d", hostAddr.IP, ExternalControlPort))
+	default:
+		return nil, validationErrorf("unknown external control version %q", version)
+	}
+}
+
+// streamVersion returns the external control protocol version to use,
+// consulting the panel model unless StreamVersion is set.
+func (c Client) streamVersion() (string, error) {
+	if c.StreamVersion != "" {
+		return c.StreamVersion, nil
+	}
+
+	panelInfo, err := c.GetPanelInfo()
+	if err != nil {
+		return "", err
+	}
+	return streamVersionForModel(panelInfo.Model), nil
+}
+
+// streamVersionForModel returns the external control protocol version
+// spoken by a Nanoleaf model. The original Light Panels (Aurora) only
+// support v1; everything since uses v2.
+func streamVersionForModel(model string) string {
+	if model == modelLightPanels {
+		return StreamV1
+	}
+	return StreamV2
 }
 
 // SetPanelColor represents a frame of external color data.
@@ -296,34 +586,63 @@ type SetPanelColor struct {
 
 // SetCustomColors sets individual Nanoleaf pane colors.
 func (c Client) SetCustomColors(frames []SetPanelColor) error {
-	err := c.startExternalControl()
+	stream, err := c.OpenStream()
 	if err != nil {
 		return err
 	}
+	defer stream.Close()
 
-	hostAddr, err := net.ResolveTCPAddr("tcp", c.Host)
-	if err != nil {
-		return err
+	for _, frame := range frames {
+		stream.SetPanel(frame.PanelID, frame.Red, frame.Green, frame.Blue, frame.TransitionTime)
 	}
+	return stream.Flush()
+}
 
-	laddr, err := net.ResolveUDPAddr("udp", ":0")
-	if err != nil {
-		return err
+// encodeFrames encodes panel colors as an external control packet.
+func encodeFrames(version string, frames []SetPanelColor) ([]byte, error) {
+	if version == StreamV1 {
+		return encodeFramesV1(frames)
 	}
+	return encodeFramesV2(frames)
+}
 
-	raddr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("%s:%d", hostAddr.IP, ExternalControlPort))
-	if err != nil {
-		return err
+// encodeFramesV1 encodes panel colors in the v1 (Light Panels) format, which
+// uses single bytes for counts, panel IDs and transition times.
+func encodeFramesV1(frames []SetPanelColor) ([]byte, error) {
+	numPanels := len(frames)
+	if numPanels > math.MaxUint8 {
+		return nil, validationErrorf("Expected between 0-%d panels, got %d", math.MaxUint8, numPanels)
 	}
 
-	conn, err := net.DialUDP("udp", laddr, raddr)
-	if err != nil {
-		return err
+	headerSize := 1
+	panelFrameSize := 7
+	buf := make([]byte, headerSize+panelFrameSize*numPanels)
+	buf[0] = uint8(numPanels)
+	for i, panel := range frames {
+		if panel.PanelID > math.MaxUint8 {
+			return nil, validationErrorf("Expected panel ID between 0-%d, got %d", math.MaxUint8, panel.PanelID)
+		}
+		if panel.TransitionTime > math.MaxUint8 {
+			return nil, validationErrorf("Expected transition time between 0-%d, got %d", math.MaxUint8, panel.TransitionTime)
+		}
+
+		offset := headerSize + panelFrameSize*i
+		buf[offset] = uint8(panel.PanelID)
+		buf[offset+1] = 1 // frames per panel
+		buf[offset+2] = panel.Red
+		buf[offset+3] = panel.Green
+		buf[offset+4] = panel.Blue
+		buf[offset+5] = panel.White
+		buf[offset+6] = uint8(panel.TransitionTime)
 	}
+	return buf, nil
+}
 
+// encodeFramesV2 encodes panel colors in the v2 format.
+func encodeFramesV2(frames []SetPanelColor) ([]byte, error) {
 	numPanels := len(frames)
-	if numPanels < 0 || numPanels > math.MaxUint16 {
-		return fmt.Errorf("Expected between 0-%d panels, got %d", math.MaxUint16, numPanels)
+	if numPanels > math.MaxUint16 {
+		return nil, validationErrorf("Expected between 0-%d panels, got %d", math.MaxUint16, numPanels)
 	}
 
 	headerSize := 2
@@ -340,10 +659,7 @@ func (c Client) SetCustomColors(frames []SetPanelColor) error {
 		buf[offset+5] = panel.White
 		binary.BigEndian.PutUint16(buf[offset+6:], panel.TransitionTime)
 	}
-
-	conn.Write(buf)
-	conn.Close()
-	return nil
+	return buf, nil
 }
 
 // BrightnessProperty represents the brightness of the Nanoleaf.
@@ -390,6 +706,21 @@ type State struct {
 	ColorMode        string                    `json:"colorMode,omitempty"`
 }
 
+// externalControlResponse represents the response to a v1 `extControl`
+// display request.
+type externalControlResponse struct {
+	IPAddr   string `json:"streamControlIpAddr"`
+	Port     int    `json:"streamControlPort"`
+	Protocol string `json:"streamControlProtocol"`
+}
+
+// panelLayoutRequest represents a JSON PUT body for `panelLayout`.
+type panelLayoutRequest struct {
+	GlobalOrientation struct {
+		Value int `json:"value"`
+	} `json:"globalOrientation"`
+}
+
 // effectsSelectRequest represents a JSON PUT body for `effects/select`.
 type effectsSelectRequest struct {
 	Select string `json:"select"`
@@ -430,3 +761,65 @@ func rgbToHSL(red, green, blue int) (int, int, int) {
 
 	return int(math.Round(h)), int(math.Round(100 * s)), int(math.Round(100 * l))
 }
+
+func rgbToHSV(red, green, blue int) (int, int, int) {
+	r := float64(red) / 255.0
+	g := float64(green) / 255.0
+	b := float64(blue) / 255.0
+
+	min := math.Min(math.Min(r, g), b)
+	max := math.Max(math.Max(r, g), b)
+
+	c := max - min
+	v := max
+
+	if c == 0 { // achromatic
+		return 0, 0, int(math.Round(100 * v))
+	}
+
+	h := 0.0
+	switch v {
+	case r:
+		h = 0 + (g-b)/c
+	case g:
+		h = 2 + (b-r)/c
+	case b:
+		h = 4 + (r-g)/c
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+
+	s := c / v
+
+	return int(math.Round(h)), int(math.Round(100 * s)), int(math.Round(100 * v))
+}
+
+func hsvToRGB(hue, sat, value int) (int, int, int) {
+	h := math.Mod(float64(hue), 360) / 60
+	s := float64(sat) / 100
+	v := float64(value) / 100
+
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(h, 2)-1))
+	m := v - c
+
+	var r, g, b float64
+	switch int(h) {
+	case 0:
+		r, g, b = c, x, 0
+	case 1:
+		r, g, b = x, c, 0
+	case 2:
+		r, g, b = 0, c, x
+	case 3:
+		r, g, b = 0, x, c
+	case 4:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+
+	return int(math.Round(255 * (r + m))), int(math.Round(255 * (g + m))), int(math.Round(255 * (b + m)))
+}
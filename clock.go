@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+func doClockCommand(client Client, colors *ini.Section, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf clock [--12h] [--color <color>] [--minute-color <color>] [--background <color>]")
+	}
+
+	fs := flag.NewFlagSet("clock", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	twelveHour := fs.Bool("12h", false, "Show a 12-hour clock")
+	colorName := fs.String("color", "white", "Color of the digits, or of the hours")
+	minuteColorName := fs.String("minute-color", "deepskyblue", "Color of the minutes, when there's no room for digits")
+	backgroundName := fs.String("background", "black", "Background color")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return usage()
+	}
+
+	var palette [3]RGB
+	for i, name := range []string{*colorName, *minuteColorName, *backgroundName} {
+		c, err := parseColor(name, colors)
+		if err != nil {
+			return err
+		}
+		palette[i] = client.calibrated(c)
+	}
+	fg, minuteColor, bg := palette[0], palette[1], palette[2]
+
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	polygons := layoutPolygons(panelInfo)
+	if len(polygons) == 0 {
+		return errors.New("no panels in layout")
+	}
+
+	cells, cols, rows := layoutGrid(polygons)
+	digits := cols >= len(renderText("00:00")[0]) && rows >= fontHeight
+	panels := panelsLeftToRight(lightPanels(panelInfo))
+
+	return streamFrames(client, func() ([]SetPanelColor, time.Duration, bool, error) {
+		now := time.Now()
+		hour := now.Hour()
+		if *twelveHour {
+			hour %= 12
+		}
+
+		colors := make(map[uint16]RGB, len(cells))
+		if digits {
+			text := fmt.Sprintf("%02d:%02d", hour, now.Minute())
+			if *twelveHour {
+				if hour == 0 {
+					hour = 12
+				}
+				text = fmt.Sprintf("%2d:%02d", hour, now.Minute())
+			}
+
+			bitmap := renderText(text)
+			left := (cols - len(bitmap[0])) / 2
+			top := (rows - fontHeight) / 2
+			for id, cell := range cells {
+				x, y := cell.Col-left, cell.Row-top
+				colors[id] = bg
+				if x >= 0 && x < len(bitmap[0]) && y >= 0 && y < fontHeight && bitmap[y][x] {
+					colors[id] = fg
+				}
+			}
+		} else {
+			// Panels fill from left to right: the hour's share of the
+			// day (or half day) in the hour color, the minute's share
+			// of the hour in the minute color, and a blend where they
+			// overlap.
+			hours := 24.0
+			if *twelveHour {
+				hours = 12
+			}
+			hourFill := (float64(hour) + float64(now.Minute())/60) / hours
+			minuteFill := float64(now.Minute()) / 60
+
+			for i, panel := range panels {
+				p := (float64(i) + 0.5) / float64(len(panels))
+				c := bg
+				switch {
+				case p < hourFill && p < minuteFill:
+					c = blend(fg, minuteColor, 0.5)
+				case p < hourFill:
+					c = fg
+				case p < minuteFill:
+					c = minuteColor
+				}
+				colors[uint16(panel.PanelID)] = c
+			}
+		}
+
+		// Update shortly after each second.
+		delay := now.Truncate(time.Second).Add(time.Second).Sub(now)
+		return panelColorFrame(colors, 0), delay, true, nil
+	})
+}
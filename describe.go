@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// hueNames names ranges of the color wheel, each starting at the given
+// hue in degrees and ending where the next begins.
+var hueNames = []struct {
+	Start int
+	Name  string
+}{
+	{0, "red"},
+	{15, "orange"},
+	{45, "yellow"},
+	{70, "green"},
+	{160, "cyan"},
+	{200, "blue"},
+	{260, "purple"},
+	{290, "pink"},
+	{340, "red"},
+}
+
+// Describe summarizes the Nanoleaf state as a sentence, e.g. "On, 40%
+// brightness, warm white 2700K".
+func Describe(panelInfo *PanelInfo) string {
+	state := panelInfo.State
+	if state.On == nil || !state.On.Value {
+		return "Off"
+	}
+
+	parts := []string{"On"}
+	if state.Brightness != nil {
+		parts = append(parts, fmt.Sprintf("%d%% brightness", state.Brightness.Value))
+	}
+
+	switch state.ColorMode {
+	case "ct":
+		if state.ColorTemperature != nil {
+			parts = append(parts, describeTemperature(state.ColorTemperature.Value))
+		}
+	case "hs":
+		if state.Hue != nil && state.Saturation != nil {
+			parts = append(parts, describeHueSaturation(state.Hue.Value, state.Saturation.Value))
+		}
+	case "effect":
+		if panelInfo.Effects.Selected != "" {
+			parts = append(parts, fmt.Sprintf("effect '%s' running", panelInfo.Effects.Selected))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// describeTemperature names a color temperature, using its friendly name
+// when it has one.
+func describeTemperature(kelvin int) string {
+	for name, value := range namedTemperatures {
+		if value == kelvin {
+			return fmt.Sprintf("%s white %dK", name, kelvin)
+		}
+	}
+	return fmt.Sprintf("white %dK", kelvin)
+}
+
+// describeHueSaturation names the color closest to a hue and saturation.
+func describeHueSaturation(hue, saturation int) string {
+	if saturation < 10 {
+		return "white"
+	}
+
+	name := hueNames[0].Name
+	for _, h := range hueNames {
+		if hue >= h.Start {
+			name = h.Name
+		}
+	}
+
+	if saturation < 50 {
+		name = "pale " + name
+	}
+	return name
+}
+
+func doDescribeCommand(client Client, args []string) error {
+	fs := flag.NewFlagSet("describe", flag.ContinueOnError)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return usageError("usage: picoleaf describe")
+	}
+
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	fmt.Println(Describe(panelInfo))
+	return nil
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAPIPort is the TCP port of the Nanoleaf REST API.
+const DefaultAPIPort = 16021
+
+// ssdpAddr is the SSDP multicast group and port.
+const ssdpAddr = "239.255.255.250:1900"
+
+// ssdpSearchTargets are the SSDP search targets Nanoleaf controllers answer to.
+var ssdpSearchTargets = []string{
+	"nanoleaf_aurora:light",
+	"nanoleaf:nl29",
+	"nanoleaf:nl42",
+}
+
+// maxScanAddresses limits the size of subnets that Scan will probe.
+const maxScanAddresses = 1 << 16
+
+// scanConcurrency is the number of hosts Scan probes at once.
+const scanConcurrency = 64
+
+// ErrNotPairing is returned by Pair when the Nanoleaf is not in pairing mode.
+var ErrNotPairing = errors.New("not in pairing mode")
+
+// Device represents a Nanoleaf found on the local network.
+type Device struct {
+	ID       string    `json:"id,omitempty"`
+	Name     string    `json:"name,omitempty"`
+	Host     string    `json:"host"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// Discover searches the local network for Nanoleaf controllers via SSDP,
+// collecting responses until the timeout elapses.
+func Discover(timeout time.Duration) ([]Device, error) {
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	raddr, err := net.ResolveUDPAddr("udp4", ssdpAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, st := range ssdpSearchTargets {
+		msg := "M-SEARCH * HTTP/1.1\r\n" +
+			"HOST: " + ssdpAddr + "\r\n" +
+			"MAN: \"ssdp:discover\"\r\n" +
+			"MX: 2\r\n" +
+			"ST: " + st + "\r\n\r\n"
+		_, err = conn.WriteTo([]byte(msg), raddr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = conn.SetReadDeadline(time.Now().Add(timeout))
+	if err != nil {
+		return nil, err
+	}
+
+	var devices []Device
+	seen := make(map[string]bool)
+	buf := make([]byte, 2048)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				break
+			}
+			return devices, err
+		}
+
+		device, ok := parseSSDPResponse(buf[:n])
+		if !ok || seen[device.Host] {
+			continue
+		}
+		seen[device.Host] = true
+		devices = append(devices, device)
+	}
+
+	return devices, nil
+}
+
+// parseSSDPResponse extracts a Device from an SSDP search response.
+func parseSSDPResponse(data []byte) (Device, bool) {
+	res, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), nil)
+	if err != nil {
+		return Device{}, false
+	}
+	res.Body.Close()
+
+	if !strings.HasPrefix(res.Header.Get("St"), "nanoleaf") {
+		return Device{}, false
+	}
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	if err != nil || location.Host == "" {
+		return Device{}, false
+	}
+
+	device := Device{
+		ID:       res.Header.Get("Nl-Deviceid"),
+		Name:     res.Header.Get("Nl-Devicename"),
+		Host:     location.Host,
+		LastSeen: time.Now(),
+	}
+	return device, true
+}
+
+// Scan probes every address in an IPv4 subnet for the Nanoleaf API, for
+// networks where SSDP is filtered. Scanned devices have no name or ID.
+func Scan(cidr string, timeout time.Duration) ([]Device, error) {
+	addrs, err := subnetAddresses(cidr)
+	if err != nil {
+		return nil, err
+	}
+
+	client := http.Client{Timeout: timeout}
+	hosts := make(chan string)
+	found := make(chan Device)
+
+	var wg sync.WaitGroup
+	for i := 0; i < scanConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for host := range hosts {
+				if probeNanoleaf(client, host) {
+					found <- Device{Host: host, LastSeen: time.Now()}
+				}
+			}
+		}()
+	}
+
+	go func() {
+		for _, addr := range addrs {
+			hosts <- net.JoinHostPort(addr.String(), strconv.Itoa(DefaultAPIPort))
+		}
+		close(hosts)
+		wg.Wait()
+		close(found)
+	}()
+
+	var devices []Device
+	for device := range found {
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
+
+// probeNanoleaf reports whether host answers like the Nanoleaf API. Without
+// a token, the API rejects requests as unauthorized.
+func probeNanoleaf(client http.Client, host string) bool {
+	res, err := client.Get(fmt.Sprintf("http://%s/api/v1/", host))
+	if err != nil {
+		return false
+	}
+	res.Body.Close()
+	return res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden
+}
+
+// subnetAddresses returns the host addresses in an IPv4 subnet, excluding
+// the network and broadcast addresses where they exist.
+func subnetAddresses(cidr string) ([]net.IP, error) {
+	_, subnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+
+	network := subnet.IP.To4()
+	if network == nil {
+		return nil, fmt.Errorf("%s is not an IPv4 subnet", cidr)
+	}
+
+	ones, bits := subnet.Mask.Size()
+	size := 1 << uint(bits-ones)
+	if size > maxScanAddresses {
+		return nil, fmt.Errorf("%s is too large to scan", cidr)
+	}
+
+	first, last := 0, size-1
+	if size > 2 {
+		first, last = 1, size-2
+	}
+
+	start := binary.BigEndian.Uint32(network)
+	addrs := make([]net.IP, 0, last-first+1)
+	for i := first; i <= last; i++ {
+		ip := make(net.IP, 4)
+		binary.BigEndian.PutUint32(ip, start+uint32(i))
+		addrs = append(addrs, ip)
+	}
+	return addrs, nil
+}
+
+// deviceCachePath returns the path of the discovered device cache.
+func deviceCachePath() (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "devices.json"), nil
+}
+
+// LoadDeviceCache returns the devices found by previous discoveries.
+func LoadDeviceCache() ([]Device, error) {
+	path, err := deviceCachePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var devices []Device
+	err = json.Unmarshal(data, &devices)
+	return devices, err
+}
+
+// UpdateDeviceCache merges newly found devices into the device cache and
+// returns the merged list. Devices are matched by host; a new sighting
+// keeps previously known names and IDs.
+func UpdateDeviceCache(found []Device) ([]Device, error) {
+	devices, err := LoadDeviceCache()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range found {
+		merged := false
+		for i, d := range devices {
+			if d.Host != f.Host {
+				continue
+			}
+			if f.ID == "" {
+				f.ID = d.ID
+			}
+			if f.Name == "" {
+				f.Name = d.Name
+			}
+			devices[i] = f
+			merged = true
+			break
+		}
+		if !merged {
+			devices = append(devices, f)
+		}
+	}
+
+	path, err := deviceCachePath()
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(devices, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return devices, ioutil.WriteFile(path, data, 0644)
+}
+
+// Pair requests a new access token from a Nanoleaf in pairing mode.
+func Pair(host string) (string, error) {
+	url := fmt.Sprintf("http://%s/api/v1/new", host)
+	res, err := http.Post(url, "application/json", nil)
+	if err != nil {
+		return "", &NetworkError{Op: "pair", Err: err}
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusForbidden {
+		return "", ErrNotPairing
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", &APIError{Method: http.MethodPost, Path: "new", StatusCode: res.StatusCode}
+	}
+
+	var body struct {
+		AuthToken string `json:"auth_token"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&body)
+	if err != nil {
+		return "", err
+	}
+	return body.AuthToken, nil
+}
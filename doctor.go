@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// Doctor connection checks.
+const (
+	doctorDialTimeout = 3 * time.Second
+	doctorDialCount   = 3
+	doctorSlowLatency = 100 * time.Millisecond
+)
+
+// pairFix is the fix for a missing or rejected access token.
+const pairFix = "hold the controller's on-off button for 5-7 seconds until the lights flash, then run `picoleaf pair <host>`"
+
+// doctorReport prints the results of checks, counting the failures.
+type doctorReport struct {
+	failures int
+}
+
+func (r *doctorReport) ok(format string, args ...interface{}) {
+	fmt.Printf("  ✓ %s\n", fmt.Sprintf(format, args...))
+}
+
+func (r *doctorReport) warn(fix string, format string, args ...interface{}) {
+	fmt.Printf("  ! %s\n", fmt.Sprintf(format, args...))
+	fmt.Printf("    fix: %s\n", fix)
+}
+
+func (r *doctorReport) fail(fix string, format string, args ...interface{}) {
+	r.failures++
+	fmt.Printf("  ✗ %s\n", fmt.Sprintf(format, args...))
+	fmt.Printf("    fix: %s\n", fix)
+}
+
+// err returns an error counting the failures, or nil if there were none.
+func (r *doctorReport) err() error {
+	if r.failures == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d problem(s) found", r.failures)
+}
+
+func doDoctorCommand(configFilePath string, args []string) error {
+	if len(args) != 0 {
+		return usageError("usage: picoleaf doctor")
+	}
+
+	var report doctorReport
+	fmt.Println("Config file", configFilePath)
+	if _, err := os.Stat(configFilePath); os.IsNotExist(err) {
+		report.fail(pairFix, "not found")
+		return report.err()
+	}
+	cfg, err := ini.Load(configFilePath)
+	if err != nil {
+		report.fail("correct the config file, or move it aside and pair again", "invalid: %v", err)
+		return report.err()
+	}
+	report.ok("valid")
+
+	devices := configuredDevices(cfg)
+	if *device != "" {
+		devices = []string{*device}
+	}
+	if len(devices) == 0 {
+		report.fail(pairFix, "no devices configured")
+	}
+
+	for _, name := range devices {
+		fmt.Println()
+		section, err := deviceSection(cfg, name)
+		if name == "" {
+			name = "default"
+		}
+		if err != nil {
+			fmt.Printf("Device %s\n", name)
+			report.fail("check -device matches a [device <name>] section in the config file", "not configured")
+			continue
+		}
+		fmt.Printf("Device %s (%s)\n", name, section.Key("host").String())
+		doctorDevice(&report, cfg, section)
+	}
+
+	fmt.Println()
+	if report.failures > 0 {
+		return report.err()
+	}
+	fmt.Println("No problems found")
+	return nil
+}
+
+// doctorDevice checks a device's configuration, connection and access
+// token, stopping at the first failure since later checks depend on it.
+func doctorDevice(report *doctorReport, cfg *ini.File, section *ini.Section) {
+	host := section.Key("host").String()
+	if host == "" {
+		report.fail("run `picoleaf discover` to find the address, then `picoleaf pair <host>`", "no host configured")
+		return
+	}
+	if section.Key("access_token").String() == "" {
+		report.fail(pairFix, "no access token configured")
+		return
+	}
+	version := section.Key("stream_version").String()
+	if version != "" && version != StreamV1 && version != StreamV2 {
+		report.fail("set stream_version to v1 or v2, or remove it", "invalid stream_version %q", version)
+		return
+	}
+
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil {
+		report.fail(fmt.Sprintf("set host to %s", net.JoinHostPort(host, fmt.Sprint(DefaultAPIPort))), "host has no port")
+		return
+	}
+	addrs, err := net.LookupHost(hostname)
+	if err != nil {
+		report.fail("check the host name, or use the address printed by `picoleaf discover`", "failed to resolve %s: %v", hostname, err)
+		return
+	}
+	report.ok("resolved %s to %s", hostname, addrs[0])
+
+	var total time.Duration
+	for i := 0; i < doctorDialCount; i++ {
+		start := time.Now()
+		conn, err := net.DialTimeout("tcp", host, doctorDialTimeout)
+		if err != nil {
+			report.fail("check Nanoleaf is powered and on the same network as this computer; if its address changed, run `picoleaf discover` and update host", "failed to connect to port %s: %v", port, err)
+			return
+		}
+		total += time.Since(start)
+		conn.Close()
+	}
+	latency := total / doctorDialCount
+	if latency > doctorSlowLatency {
+		report.warn("move the controller or access point closer together, or check for Wi-Fi interference", "connected to port %s, but slowly (%s)", port, latency.Round(time.Millisecond))
+	} else {
+		report.ok("connected to port %s in %s", port, latency.Round(time.Millisecond))
+	}
+
+	client := newClient(section)
+	client.Cache = nil
+	panelInfo, err := client.GetPanelInfo()
+	if errors.Is(err, ErrAuth) {
+		report.fail(pairFix, "access token rejected")
+		return
+	}
+	if err != nil {
+		report.fail("check the host is a Nanoleaf controller, and not another device that took its address", "request failed: %v", err)
+		return
+	}
+	report.ok("access token accepted")
+	report.ok("%s, model %s, firmware %s", panelInfo.Name, panelInfo.Model, panelInfo.FirmwareVersion)
+
+	doctorQuirks(report, cfg, section, panelInfo)
+}
+
+// doctorQuirks warns about settings that don't suit the device's model.
+func doctorQuirks(report *doctorReport, cfg *ini.File, section *ini.Section, panelInfo *PanelInfo) {
+	modelVersion := streamVersionForModel(panelInfo.Model)
+	if version := section.Key("stream_version").String(); version != "" && version != modelVersion {
+		report.warn("remove stream_version so the version is chosen from the model", "stream_version is %s, but model %s uses %s", version, panelInfo.Model, modelVersion)
+	}
+
+	if panelInfo.Model == modelLightPanels && (len(cfg.Section("touch").Keys()) > 0 || len(cfg.Section("gestures").Keys()) > 0) {
+		report.warn("touch gestures need panels that sense touch, like Canvas or Shapes", "touch gestures are configured, but Light Panels can't sense touch")
+	}
+
+	if panelInfo.PanelLayout.Layout.NumPanels == 0 {
+		report.warn("check the panels are connected to the controller", "no panels in layout")
+	}
+}
@@ -0,0 +1,462 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+func doEffectDeleteCommand(client Client, args []string) error {
+	fs := flag.NewFlagSet("delete", flag.ContinueOnError)
+	force := fs.Bool("force", false, "Delete without asking for confirmation")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		return usageError("usage: picoleaf effect delete [--force] <name> ...")
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for _, name := range names {
+		if !*force {
+			fmt.Printf("Delete effect %q? [y/N] ", name)
+			if !scanner.Scan() {
+				fmt.Println()
+				return nil
+			}
+			answer := strings.ToLower(strings.TrimSpace(scanner.Text()))
+			if answer != "y" && answer != "yes" {
+				continue
+			}
+		}
+
+		err := client.DeleteEffect(name)
+		if err != nil {
+			return fmt.Errorf("failed to delete effect: %w", err)
+		}
+	}
+	return nil
+}
+
+func doEffectImportCommand(client Client, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	name := fs.String("name", "", "Name to give the effect, instead of the one in the file")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return usageError("usage: picoleaf effect import <file> [--name <name>]")
+	}
+
+	// The file is sent as is, apart from the name and safety limits, so
+	// fields Effect doesn't model survive the round trip.
+	_, data, err := readEffectFile(positional[0])
+	if err != nil {
+		return err
+	}
+	data, err = client.limitedData(data)
+	if err == nil && *name != "" {
+		data, err = setEffectField(data, "animName", *name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to prepare effect: %w", err)
+	}
+
+	err = client.AddEffectData(data)
+	if err != nil {
+		return fmt.Errorf("failed to add effect: %w", err)
+	}
+	return nil
+}
+
+func doEffectPreviewCommand(client Client, args []string) error {
+	fs := flag.NewFlagSet("preview", flag.ContinueOnError)
+	duration := fs.Duration("for", 30*time.Second, "How long to play the effect")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 || *duration <= 0 {
+		return usageError("usage: picoleaf effect preview <name|file> [--for <duration>]")
+	}
+
+	if _, statErr := os.Stat(positional[0]); statErr == nil {
+		var effect Effect
+		effect, _, err = readEffectFile(positional[0])
+		if err != nil {
+			return err
+		}
+		err = client.PreviewEffect(effect, *duration)
+	} else {
+		err = client.PreviewNamedEffect(positional[0], *duration)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to preview effect: %w", err)
+	}
+	return nil
+}
+
+// readEffectFile reads and validates an exported effect, returning it both
+// decoded and as read.
+func readEffectFile(path string) (Effect, json.RawMessage, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Effect{}, nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var effect Effect
+	err = json.Unmarshal(data, &effect)
+	if err != nil {
+		return Effect{}, nil, fmt.Errorf("failed to parse effect: %w", err)
+	}
+
+	err = effect.Validate()
+	if err != nil {
+		return Effect{}, nil, fmt.Errorf("invalid effect: %w", err)
+	}
+	return effect, data, nil
+}
+
+func doEffectRandomCommand(client Client, section *ini.Section, args []string) error {
+	fs := flag.NewFlagSet("random", flag.ContinueOnError)
+	exclude := fs.String("exclude", "", "Comma-separated effects never to pick")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return usageError("usage: picoleaf effect random [--exclude <name>,...]")
+	}
+
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	candidates := panelInfo.Effects.List
+	if section.HasKey("random_effects") {
+		candidates = section.Key("random_effects").Strings(",")
+	}
+
+	excluded := map[string]bool{panelInfo.Effects.Selected: true}
+	for _, name := range strings.Split(*exclude, ",") {
+		excluded[strings.TrimSpace(name)] = true
+	}
+
+	var pool []string
+	for _, name := range candidates {
+		if !excluded[name] {
+			pool = append(pool, name)
+		}
+	}
+	if len(pool) == 0 {
+		return errors.New("no effects to choose from")
+	}
+
+	rand.Seed(time.Now().UnixNano())
+	name := pool[rand.Intn(len(pool))]
+	err = client.SelectEffect(name)
+	if err != nil {
+		return fmt.Errorf("failed to select effect: %w", err)
+	}
+	fmt.Println(name)
+	return nil
+}
+
+// doEffectCycleCommand selects the effect offset places from the selected
+// one in the effects list, wrapping around at either end.
+func doEffectCycleCommand(client Client, name string, offset int, args []string) error {
+	if len(args) != 0 {
+		return usageError("usage: picoleaf effect " + name)
+	}
+
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	list := panelInfo.Effects.List
+	if len(list) == 0 {
+		return errors.New("no effects installed")
+	}
+
+	// With no effect from the list selected, next starts at the first
+	// effect and prev at the last.
+	current := -1
+	if offset < 0 {
+		current = len(list)
+	}
+	for i, effect := range list {
+		if effect == panelInfo.Effects.Selected {
+			current = i
+			break
+		}
+	}
+
+	next := ((current+offset)%len(list) + len(list)) % len(list)
+	err = client.SelectEffect(list[next])
+	if err != nil {
+		return fmt.Errorf("failed to select effect: %w", err)
+	}
+	fmt.Println(list[next])
+	return nil
+}
+
+func doEffectRotateCommand(client Client, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf effect rotate [--every <duration>] [--list <name>,...]")
+	}
+
+	fs := flag.NewFlagSet("rotate", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	every := fs.Duration("every", 15*time.Minute, "How long to show each effect")
+	names := fs.String("list", "", "Comma-separated effects to rotate through, instead of all of them")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 || *every <= 0 {
+		return usage()
+	}
+
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	list := panelInfo.Effects.List
+	if *names != "" {
+		list = nil
+		for _, name := range strings.Split(*names, ",") {
+			list = append(list, strings.TrimSpace(name))
+		}
+	}
+	if len(list) == 0 {
+		return errors.New("no effects to rotate through")
+	}
+
+	// The snapshot restores a solid color too, which can't be selected like
+	// an effect.
+	snapshot, err := client.Snapshot()
+	if err != nil {
+		return fmt.Errorf("failed to get Nanoleaf state: %w", err)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	ticker := time.NewTicker(*every)
+	defer ticker.Stop()
+
+	for i := 0; ; i = (i + 1) % len(list) {
+		err := client.SelectEffect(list[i])
+		if err != nil {
+			fmt.Println("error: failed to select effect:", err)
+		} else if client.Verbose {
+			fmt.Println(list[i])
+		}
+
+		select {
+		case <-ticker.C:
+		case <-signals:
+			err := client.ApplySnapshot(*snapshot)
+			if err != nil {
+				return fmt.Errorf("failed to restore state: %w", err)
+			}
+			return nil
+		}
+	}
+}
+
+func doEffectRenameCommand(client Client, args []string) error {
+	if len(args) != 2 {
+		return usageError("usage: picoleaf effect rename <old> <new>")
+	}
+
+	err := client.RenameEffect(args[0], args[1])
+	if err != nil {
+		return fmt.Errorf("failed to rename effect: %w", err)
+	}
+	return nil
+}
+
+func doEffectCompileCommand(client Client, colors *ini.Section, args []string) error {
+	fs := flag.NewFlagSet("compile", flag.ContinueOnError)
+	name := fs.String("name", "", "Print a custom effect with this name as JSON, instead of its animData")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return usageError("usage: picoleaf effect compile <file> [--name <name>]")
+	}
+
+	f, err := os.Open(positional[0])
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	defer f.Close()
+
+	animation, loop, err := ParseAnimationSource(f, colors)
+	if err != nil {
+		return fmt.Errorf("failed to compile animation: %w", err)
+	}
+
+	animData := EncodeAnimData(animation)
+	if *name == "" {
+		fmt.Println(animData)
+		return nil
+	}
+
+	effect := Effect{
+		Name:     *name,
+		Type:     "custom",
+		AnimData: animData,
+		Palette:  []PaletteColor{},
+		Loop:     loop,
+	}
+	data, err := json.MarshalIndent(effect, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode effect: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func doEffectExportCommand(client Client, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := fs.String("o", "", "Write the effect to a file instead of stdout")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return usageError("usage: picoleaf effect export <name> [-o <file>]")
+	}
+
+	// The definition is written as the device sent it, since Effect doesn't
+	// model every field, and importing it should reproduce the effect.
+	effect, err := client.RequestEffectData(positional[0])
+	if err != nil {
+		return fmt.Errorf("failed to get effect: %w", err)
+	}
+
+	var buf bytes.Buffer
+	err = json.Indent(&buf, effect, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode effect: %w", err)
+	}
+	buf.WriteByte('\n')
+	data := buf.Bytes()
+
+	if *output == "" {
+		os.Stdout.Write(data)
+		return nil
+	}
+
+	err = ioutil.WriteFile(*output, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+func doEffectGCCommand(client Client, args []string) error {
+	fs := flag.NewFlagSet("gc", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "List the effects that would be deleted")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return usageError("usage: picoleaf effect gc [--dry-run]")
+	}
+
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	for _, name := range panelInfo.Effects.List {
+		if !IsGeneratedEffect(name) || name == panelInfo.Effects.Selected {
+			continue
+		}
+
+		fmt.Println(name)
+		if *dryRun {
+			continue
+		}
+
+		err := client.DeleteEffect(name)
+		if err != nil {
+			return fmt.Errorf("failed to delete effect: %w", err)
+		}
+	}
+	return nil
+}
+
+func doEffectShowCommand(client Client, args []string) error {
+	if len(args) != 1 {
+		return usageError("usage: picoleaf effect show <name>")
+	}
+
+	effect, err := client.RequestEffect(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to get effect: %w", err)
+	}
+
+	fmt.Println("Name:   ", effect.Name)
+	fmt.Println("Type:   ", effect.Type)
+	if effect.PluginType != "" || effect.PluginUUID != "" {
+		fmt.Printf("Plugin:  %s (%s)\n", effect.PluginType, effect.PluginUUID)
+	}
+	if effect.ColorType != "" {
+		fmt.Println("Colors: ", effect.ColorType)
+	}
+	fmt.Println("Loop:   ", effect.Loop)
+
+	if len(effect.Palette) > 0 {
+		fmt.Println()
+		fmt.Println("Palette:")
+		for _, color := range effect.Palette {
+			r, g, b := hsvToRGB(color.Hue, color.Saturation, color.Brightness)
+			swatch := ansiSwatch(RGB{uint8(r), uint8(g), uint8(b)})
+			fmt.Printf("  %s hue %3d°  sat %3d  bri %3d", swatch, color.Hue, color.Saturation, color.Brightness)
+			if color.Probability != 0 {
+				fmt.Printf("  p=%g", color.Probability)
+			}
+			fmt.Println()
+		}
+	}
+
+	if len(effect.PluginOptions) > 0 {
+		fmt.Println()
+		fmt.Println("Options:")
+		for _, option := range effect.PluginOptions {
+			fmt.Printf("  %s: %v\n", option.Name, option.Value)
+		}
+	}
+
+	if effect.AnimData != "" {
+		animation, err := ParseAnimData(effect.AnimData)
+		if err == nil {
+			fmt.Println()
+			fmt.Println("Panels:", len(animation))
+		}
+	}
+	return nil
+}
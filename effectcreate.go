@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// effectPlugins are the built-in Nanoleaf color plugins offered by
+// `effect create`.
+var effectPlugins = []struct {
+	Name        string
+	UUID        string
+	Directional bool
+}{
+	{"wheel", "6970681a-20b5-4c5e-8813-bdaebc4ee4fa", true},
+	{"flow", "027842e4-e1d6-4a4c-a731-be74a1ebd4cf", true},
+	{"explode", "713518c1-d560-47db-8991-de780af71d1e", false},
+	{"fade", "b3fd723a-aae8-4c99-bf2b-087159e0ef53", false},
+	{"random", "ba632d3e-9c2b-4413-a965-510c839b3f71", false},
+	{"highlight", "70b7c636-6bf8-491f-89c1-f4103508d642", false},
+}
+
+// effectDirections are the directions accepted by directional plugins.
+var effectDirections = []string{"left", "right", "up", "down"}
+
+func doEffectCreateCommand(client Client, colors *ini.Section, args []string) error {
+	if len(args) != 0 {
+		return usageError("usage: picoleaf effect create")
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	ask := func(question, def string) (string, error) {
+		if def != "" {
+			fmt.Printf("%s [%s]: ", question, def)
+		} else {
+			fmt.Printf("%s: ", question)
+		}
+		if !scanner.Scan() {
+			fmt.Println()
+			return "", errors.New("no answer")
+		}
+		answer := strings.TrimSpace(scanner.Text())
+		if answer == "" {
+			return def, nil
+		}
+		return answer, nil
+	}
+
+	effect := Effect{
+		Type:       "plugin",
+		ColorType:  "HSB",
+		PluginType: "color",
+		Loop:       true,
+	}
+
+	var err error
+	for effect.Name == "" {
+		effect.Name, err = ask("Name", "")
+		if err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, len(effectPlugins))
+	for i, plugin := range effectPlugins {
+		names[i] = plugin.Name
+	}
+	directional := false
+	for effect.PluginUUID == "" {
+		answer, err := ask("Plugin ("+strings.Join(names, ", ")+")", "wheel")
+		if err != nil {
+			return err
+		}
+		if i := findEffectPlugin(strings.ToLower(answer)); i >= 0 {
+			effect.PluginUUID = effectPlugins[i].UUID
+			directional = effectPlugins[i].Directional
+		}
+	}
+
+	for len(effect.Palette) == 0 {
+		answer, err := ask("Colors (names or hex values, separated by spaces)", "red orange yellow")
+		if err != nil {
+			return err
+		}
+		palette, err := parsePalette(strings.Fields(answer), colors)
+		if err != nil {
+			fmt.Println("error:", err)
+			continue
+		}
+		effect.Palette = palette
+	}
+
+	transTime, err := askSeconds(ask, "Transition time in seconds", "1")
+	if err != nil {
+		return err
+	}
+	effect.PluginOptions = append(effect.PluginOptions, PluginOption{Name: "transTime", Value: transTime})
+
+	if directional {
+		var direction string
+		for direction == "" {
+			answer, err := ask("Direction ("+strings.Join(effectDirections, ", ")+")", "right")
+			if err != nil {
+				return err
+			}
+			for _, d := range effectDirections {
+				if d == strings.ToLower(answer) {
+					direction = d
+				}
+			}
+		}
+		effect.PluginOptions = append(effect.PluginOptions, PluginOption{Name: "linDirection", Value: direction})
+	} else {
+		delayTime, err := askSeconds(ask, "Delay between changes in seconds", "0")
+		if err != nil {
+			return err
+		}
+		effect.PluginOptions = append(effect.PluginOptions, PluginOption{Name: "delayTime", Value: delayTime})
+	}
+
+	err = client.AddEffect(effect)
+	if err != nil {
+		return fmt.Errorf("failed to add effect: %w", err)
+	}
+
+	answer, err := ask("Select it now? (y/n)", "y")
+	if err != nil {
+		return err
+	}
+	answer = strings.ToLower(answer)
+	if answer == "y" || answer == "yes" {
+		err = client.SelectEffect(effect.Name)
+		if err != nil {
+			return fmt.Errorf("failed to select effect: %w", err)
+		}
+	}
+	return nil
+}
+
+func doEffectFromPaletteCommand(client Client, colors *ini.Section, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf effect from-palette <color>,... [--plugin <plugin>] [--speed <seconds>] [--direction <direction>] [--name <name>]")
+	}
+
+	fs := flag.NewFlagSet("from-palette", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	pluginName := fs.String("plugin", "wheel", "Plugin to animate the palette with")
+	speed := fs.Float64("speed", 1, "Transition time in seconds")
+	direction := fs.String("direction", "right", "Direction for wheel and flow")
+	name := fs.String("name", "", "Name for the effect")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 || *speed < 0 {
+		return usage()
+	}
+
+	i := findEffectPlugin(strings.ToLower(*pluginName))
+	if i < 0 {
+		return fmt.Errorf("unknown plugin: %v", *pluginName)
+	}
+	plugin := effectPlugins[i]
+
+	colorNames := strings.Split(positional[0], ",")
+	palette, err := parsePalette(colorNames, colors)
+	if err != nil {
+		return err
+	}
+
+	effect := Effect{
+		Name:       *name,
+		Type:       "plugin",
+		ColorType:  "HSB",
+		PluginType: "color",
+		PluginUUID: plugin.UUID,
+		Palette:    palette,
+		Loop:       true,
+		PluginOptions: []PluginOption{
+			{Name: "transTime", Value: int(math.Round(*speed * 10))},
+		},
+	}
+	if effect.Name == "" {
+		effect.Name = GeneratedEffectName(plugin.Name + " " + strings.Join(colorNames, " "))
+	}
+	if plugin.Directional {
+		effect.PluginOptions = append(effect.PluginOptions, PluginOption{Name: "linDirection", Value: strings.ToLower(*direction)})
+	}
+
+	err = client.AddEffect(effect)
+	if err != nil {
+		return fmt.Errorf("failed to add effect: %w", err)
+	}
+
+	err = client.SelectEffect(effect.Name)
+	if err != nil {
+		return fmt.Errorf("failed to select effect: %w", err)
+	}
+	return nil
+}
+
+// findEffectPlugin returns the index of the named plugin in effectPlugins,
+// or -1 if there is none.
+func findEffectPlugin(name string) int {
+	for i, plugin := range effectPlugins {
+		if plugin.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// askSeconds asks for a duration in seconds until it gets a valid one, and
+// returns it in tenths of a second, as plugin options expect.
+func askSeconds(ask func(question, def string) (string, error), question, def string) (int, error) {
+	for {
+		answer, err := ask(question, def)
+		if err != nil {
+			return 0, err
+		}
+		seconds, err := strconv.ParseFloat(answer, 64)
+		if err == nil && seconds >= 0 {
+			return int(math.Round(seconds * 10)), nil
+		}
+		fmt.Println("error: expected a number of seconds")
+	}
+}
+
+// parsePalette converts color names or hex values into palette colors.
+func parsePalette(names []string, colors *ini.Section) ([]PaletteColor, error) {
+	palette := make([]PaletteColor, len(names))
+	for i, name := range names {
+		c, err := parseColor(name, colors)
+		if err != nil {
+			return nil, err
+		}
+		h, s, v := rgbToHSV(int(c.Red), int(c.Green), int(c.Blue))
+		palette[i] = PaletteColor{Hue: h, Saturation: s, Brightness: v}
+	}
+	return palette, nil
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"time"
+)
+
+// GeneratedEffectPrefix starts the names of effects that picoleaf adds to
+// the effects list, so they can be told apart and cleaned up.
+const GeneratedEffectPrefix = "picoleaf: "
+
+// GeneratedEffectName returns the reserved name for a generated effect.
+func GeneratedEffectName(name string) string {
+	if IsGeneratedEffect(name) {
+		return name
+	}
+	return GeneratedEffectPrefix + name
+}
+
+// IsGeneratedEffect reports whether an effect was added by picoleaf.
+func IsGeneratedEffect(name string) bool {
+	return strings.HasPrefix(name, GeneratedEffectPrefix)
+}
+
+// Effect represents an effect definition, as returned by the effects write
+// `request` command.
+type Effect struct {
+	Name          string         `json:"animName,omitempty"`
+	Version       string         `json:"version,omitempty"`
+	Type          string         `json:"animType"`
+	ColorType     string         `json:"colorType,omitempty"`
+	AnimData      string         `json:"animData,omitempty"`
+	Palette       []PaletteColor `json:"palette"`
+	PluginType    string         `json:"pluginType,omitempty"`
+	PluginUUID    string         `json:"pluginUuid,omitempty"`
+	PluginOptions []PluginOption `json:"pluginOptions,omitempty"`
+	Loop          bool           `json:"loop"`
+}
+
+// Validate checks that an effect definition has the fields its type
+// requires.
+func (e Effect) Validate() error {
+	if e.Name == "" {
+		return validationErrorf("effect has no name")
+	}
+
+	switch e.Type {
+	case "custom", "static":
+		if e.AnimData == "" {
+			return validationErrorf("%s effect has no animData", e.Type)
+		}
+		_, err := ParseAnimData(e.AnimData)
+		if err != nil {
+			return validationErrorf("invalid animData: %v", err)
+		}
+	case "plugin":
+		if e.PluginUUID == "" {
+			return validationErrorf("plugin effect has no pluginUuid")
+		}
+		if len(e.Palette) == 0 {
+			return validationErrorf("plugin effect has no palette")
+		}
+	case "":
+		return validationErrorf("effect has no animType")
+	}
+
+	for _, color := range e.Palette {
+		if color.Hue < 0 || color.Hue > 360 || color.Saturation < 0 || color.Saturation > 100 ||
+			color.Brightness < 0 || color.Brightness > 100 {
+			return validationErrorf("palette color out of range: hue %d, saturation %d, brightness %d",
+				color.Hue, color.Saturation, color.Brightness)
+		}
+	}
+	return nil
+}
+
+// PluginOption represents an option passed to an effect plugin.
+type PluginOption struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+// PaletteColor represents one color of an effect palette.
+type PaletteColor struct {
+	Hue         int     `json:"hue"`
+	Saturation  int     `json:"saturation"`
+	Brightness  int     `json:"brightness"`
+	Probability float64 `json:"probability,omitempty"`
+}
+
+// effectsWriteRequest represents a JSON PUT body for `effects`.
+type effectsWriteRequest struct {
+	Write interface{} `json:"write"`
+}
+
+// effectsCommand represents an effects write command that names an effect.
+type effectsCommand struct {
+	Command  string `json:"command"`
+	AnimName string `json:"animName,omitempty"`
+	NewName  string `json:"newName,omitempty"`
+	Duration int    `json:"duration,omitempty"`
+}
+
+// effectWriteCommand represents an effects write command that carries an
+// effect definition.
+type effectWriteCommand struct {
+	Command  string `json:"command"`
+	Duration int    `json:"duration,omitempty"`
+	*Effect
+}
+
+// write sends an effects write command and returns the response body.
+func (c Client) write(command interface{}) (string, error) {
+	bytes, err := json.Marshal(effectsWriteRequest{Write: command})
+	if err != nil {
+		return "", err
+	}
+	return c.Put("effects", bytes)
+}
+
+// RequestEffect returns the definition of the named effect.
+func (c Client) RequestEffect(name string) (*Effect, error) {
+	data, err := c.RequestEffectData(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var effect Effect
+	err = json.Unmarshal(data, &effect)
+	return &effect, err
+}
+
+// RequestEffectData returns the definition of the named effect as the
+// device sent it, including the fields Effect doesn't model.
+func (c Client) RequestEffectData(name string) (json.RawMessage, error) {
+	body, err := c.write(effectsCommand{Command: "request", AnimName: name})
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(body), nil
+}
+
+// RequestAllEffectsData returns the definitions of every effect in the
+// effects list as the device sent them, like RequestEffectData.
+func (c Client) RequestAllEffectsData() ([]json.RawMessage, error) {
+	body, err := c.write(effectsCommand{Command: "requestAll"})
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Animations []json.RawMessage `json:"animations"`
+	}
+	err = json.Unmarshal([]byte(body), &response)
+	return response.Animations, err
+}
+
+// limited applies the client's safety limits to a custom effect's
+// animation data.
+func (c Client) limited(effect Effect) (Effect, error) {
+	if effect.Type != "custom" || effect.AnimData == "" {
+		return effect, nil
+	}
+
+	animation, err := ParseAnimData(effect.AnimData)
+	if err != nil {
+		return effect, err
+	}
+	effect.AnimData = EncodeAnimData(c.safetyLimits().LimitAnimation(animation, effect.Loop))
+	return effect, nil
+}
+
+// DisplayEffect plays an effect without adding it to the effects list.
+func (c Client) DisplayEffect(effect Effect) error {
+	if effect.Palette == nil {
+		effect.Palette = []PaletteColor{}
+	}
+	effect, err := c.limited(effect)
+	if err != nil {
+		return err
+	}
+	_, err = c.write(effectWriteCommand{Command: "display", Effect: &effect})
+	return err
+}
+
+// PreviewEffect plays an effect for a while, after which the Nanoleaf
+// returns to its previous state.
+func (c Client) PreviewEffect(effect Effect, duration time.Duration) error {
+	if effect.Palette == nil {
+		effect.Palette = []PaletteColor{}
+	}
+	effect, err := c.limited(effect)
+	if err != nil {
+		return err
+	}
+	_, err = c.write(effectWriteCommand{Command: "displayTemp", Duration: previewSeconds(duration), Effect: &effect})
+	return err
+}
+
+// previewSeconds returns a preview duration in the whole seconds the API
+// expects, rounding up so short previews still play.
+func previewSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
+
+// PreviewNamedEffect plays an installed effect for a while, after which the
+// Nanoleaf returns to its previous state.
+func (c Client) PreviewNamedEffect(name string, duration time.Duration) error {
+	_, err := c.write(effectsCommand{Command: "displayTemp", AnimName: name, Duration: previewSeconds(duration)})
+	return err
+}
+
+// AddEffect adds an effect to the effects list, replacing any effect with
+// the same name.
+func (c Client) AddEffect(effect Effect) error {
+	if effect.Palette == nil {
+		effect.Palette = []PaletteColor{}
+	}
+	effect, err := c.limited(effect)
+	if err != nil {
+		return err
+	}
+	_, err = c.write(effectWriteCommand{Command: "add", Effect: &effect})
+	return err
+}
+
+// AddEffectData adds an effect to the effects list from its definition, as
+// returned by RequestEffectData, sending every field as is.
+func (c Client) AddEffectData(data json.RawMessage) error {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return err
+	}
+	fields["command"] = json.RawMessage(`"add"`)
+	_, err = c.write(fields)
+	return err
+}
+
+// limitedData applies the client's safety limits to an effect definition's
+// animation data, keeping the fields Effect doesn't model.
+func (c Client) limitedData(data json.RawMessage) (json.RawMessage, error) {
+	var effect Effect
+	err := json.Unmarshal(data, &effect)
+	if err != nil {
+		return nil, err
+	}
+	limited, err := c.limited(effect)
+	if err != nil || limited.AnimData == effect.AnimData {
+		return data, err
+	}
+	return setEffectField(data, "animData", limited.AnimData)
+}
+
+// setEffectField sets a field of an effect definition, keeping the rest.
+func setEffectField(data json.RawMessage, key string, value interface{}) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+	fields[key], err = json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// DeleteEffect removes the named effect from the effects list.
+func (c Client) DeleteEffect(name string) error {
+	_, err := c.write(effectsCommand{Command: "delete", AnimName: name})
+	return err
+}
+
+// RenameEffect renames an effect in the effects list.
+func (c Client) RenameEffect(name, newName string) error {
+	_, err := c.write(effectsCommand{Command: "rename", AnimName: name, NewName: newName})
+	return err
+}
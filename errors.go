@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error categories. Errors returned by Client match one of these with
+// errors.Is, and the concrete error types below can be extracted with
+// errors.As.
+var (
+	// ErrNetwork means Nanoleaf could not be reached.
+	ErrNetwork = errors.New("network error")
+
+	// ErrAuth means Nanoleaf rejected the access token.
+	ErrAuth = errors.New("authentication failed")
+
+	// ErrAPI means Nanoleaf responded with an unsuccessful status.
+	ErrAPI = errors.New("API error")
+
+	// ErrValidation means a request was rejected before being sent.
+	ErrValidation = errors.New("invalid argument")
+
+	// ErrQueued means Nanoleaf could not be reached, so a change was queued
+	// to be sent once it's back.
+	ErrQueued = errors.New("queued")
+)
+
+// NetworkError wraps a failure to communicate with Nanoleaf.
+type NetworkError struct {
+	Op  string
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Op, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrNetwork.
+func (e *NetworkError) Is(target error) bool {
+	return target == ErrNetwork
+}
+
+// APIError is returned when Nanoleaf responds with an unsuccessful status.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+// Is reports whether target is ErrAuth, for unauthorized and forbidden
+// responses, or ErrAPI, for any unsuccessful response.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrAuth:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrAPI:
+		return true
+	}
+	return false
+}
+
+// QueuedError is returned when a change is queued because Nanoleaf could
+// not be reached.
+type QueuedError struct {
+	Method string
+	Path   string
+}
+
+func (e *QueuedError) Error() string {
+	return fmt.Sprintf("Nanoleaf is unreachable, queued %s %s", e.Method, e.Path)
+}
+
+// Is reports whether target is ErrQueued.
+func (e *QueuedError) Is(target error) bool {
+	return target == ErrQueued
+}
+
+// ValidationError is returned when a request can't be represented in the
+// protocol, such as an out-of-range panel ID.
+type ValidationError struct {
+	Msg string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Msg
+}
+
+// Is reports whether target is ErrValidation.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// validationErrorf formats a ValidationError.
+func validationErrorf(format string, args ...interface{}) error {
+	return &ValidationError{Msg: fmt.Sprintf(format, args...)}
+}
+
+// publicError returns a message for err that's safe to send to the callers
+// of a daemon. Network errors only say the device is unreachable, since
+// their details include its address and access token, and access tokens
+// are redacted from anything else.
+func publicError(err error) string {
+	if errors.Is(err, ErrNetwork) {
+		return "device unreachable"
+	}
+	return redact(err.Error())
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EventType identifies a kind of event sent by the events endpoint.
+type EventType int
+
+// Event types, as numbered by the API.
+const (
+	EventState   EventType = 1
+	EventLayout  EventType = 2
+	EventEffects EventType = 3
+	EventTouch   EventType = 4
+)
+
+// eventTypeNames are the names of event types used on the command line.
+var eventTypeNames = map[EventType]string{
+	EventState:   "state",
+	EventLayout:  "layout",
+	EventEffects: "effects",
+	EventTouch:   "touch",
+}
+
+func (t EventType) String() string {
+	if name, ok := eventTypeNames[t]; ok {
+		return name
+	}
+	return strconv.Itoa(int(t))
+}
+
+// ParseEventType returns the event type with the given name.
+func ParseEventType(name string) (EventType, error) {
+	for t, n := range eventTypeNames {
+		if n == name {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown event type %q", name)
+}
+
+// eventAttributeNames are the names of the attributes reported by state,
+// layout and effects events.
+var eventAttributeNames = map[EventType]map[int]string{
+	EventState: {
+		1: "on",
+		2: "brightness",
+		3: "hue",
+		4: "sat",
+		5: "ct",
+		6: "colorMode",
+	},
+	EventLayout: {
+		1: "layout",
+		2: "globalOrientation",
+	},
+	EventEffects: {
+		1: "effect",
+	},
+}
+
+// Gesture is a touch gesture reported by touch events.
+type Gesture int
+
+// Touch gestures, as numbered by the API.
+const (
+	GestureTap        Gesture = 0
+	GestureDoubleTap  Gesture = 1
+	GestureSwipeUp    Gesture = 2
+	GestureSwipeDown  Gesture = 3
+	GestureSwipeLeft  Gesture = 4
+	GestureSwipeRight Gesture = 5
+)
+
+// gestureNames are the names of gestures used in output and the config file.
+var gestureNames = map[Gesture]string{
+	GestureTap:        "tap",
+	GestureDoubleTap:  "double-tap",
+	GestureSwipeUp:    "swipe-up",
+	GestureSwipeDown:  "swipe-down",
+	GestureSwipeLeft:  "swipe-left",
+	GestureSwipeRight: "swipe-right",
+}
+
+func (g Gesture) String() string {
+	if name, ok := gestureNames[g]; ok {
+		return name
+	}
+	return strconv.Itoa(int(g))
+}
+
+// EventItem is a single change within an event. State, layout and effects
+// events set Attr and Value; touch events set PanelID and Gesture.
+type EventItem struct {
+	Attr    int             `json:"attr,omitempty"`
+	Value   json.RawMessage `json:"value,omitempty"`
+	PanelID int             `json:"panelId,omitempty"`
+	Gesture Gesture         `json:"gesture"`
+}
+
+// Event is a batch of changes of one type sent by the events endpoint.
+type Event struct {
+	Type  EventType
+	Items []EventItem `json:"events"`
+}
+
+// AttrName returns the name of the attribute changed by item, for state,
+// layout and effects events.
+func (e Event) AttrName(item EventItem) string {
+	if name, ok := eventAttributeNames[e.Type][item.Attr]; ok {
+		return name
+	}
+	return strconv.Itoa(item.Attr)
+}
+
+// SubscribeEvents listens for events of the given types, calling handler
+// for each one as it arrives. It returns when the connection is closed or
+// handler returns an error.
+func (c Client) SubscribeEvents(types []EventType, handler func(Event) error) error {
+	ids := make([]string, len(types))
+	for i, t := range types {
+		ids[i] = strconv.Itoa(int(t))
+	}
+	path := "events?id=" + strings.Join(ids, ",")
+	op := http.MethodGet + " " + path
+
+	if c.Verbose {
+		fmt.Println(op)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, c.Endpoint(path), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	start := time.Now()
+	res, err := c.client.Do(req)
+	if err != nil {
+		c.recordExchange(req, nil, nil, nil, start, time.Time{}, err)
+		return &NetworkError{Op: op, Err: err}
+	}
+	defer res.Body.Close()
+	// The stream doesn't end, so only its headers are recorded.
+	c.recordExchange(req, nil, res, nil, start, time.Time{}, nil)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(res.Body)
+		return &APIError{
+			Method:     http.MethodGet,
+			Path:       path,
+			StatusCode: res.StatusCode,
+			Body:       string(body),
+		}
+	}
+
+	// Events are separated by blank lines, with an "id:" line giving the
+	// event type and a "data:" line holding the changes.
+	var id, data string
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if c.Verbose && line != "" {
+			fmt.Println("<===", line)
+		}
+
+		switch {
+		case strings.HasPrefix(line, "id:"):
+			id = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		case line == "" && data != "":
+			event, err := parseEvent(id, data)
+			id, data = "", ""
+			if err != nil {
+				return err
+			}
+			err = handler(event)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return &NetworkError{Op: op, Err: err}
+	}
+	return &NetworkError{Op: op, Err: fmt.Errorf("connection closed")}
+}
+
+// parseEvent decodes an event from its id and data fields.
+func parseEvent(id string, data string) (Event, error) {
+	var event Event
+	err := json.Unmarshal([]byte(data), &event)
+	if err != nil {
+		return event, fmt.Errorf("invalid event: %v", err)
+	}
+
+	t, err := strconv.Atoi(id)
+	if err != nil {
+		return event, fmt.Errorf("invalid event id %q", id)
+	}
+	event.Type = EventType(t)
+	return event, nil
+}
+
+// eventReconnectDelay is how long to wait before reconnecting after the
+// events connection drops.
+const eventReconnectDelay = 5 * time.Second
+
+func doEventsCommand(client Client, args []string) error {
+	fs := flag.NewFlagSet("events", flag.ContinueOnError)
+	typesFlag := fs.String("types", "state,effects,touch,layout", "Comma-separated event types to print")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return usageError("usage: picoleaf events [--types state,effects,touch,layout]")
+	}
+
+	var types []EventType
+	for _, name := range strings.Split(*typesFlag, ",") {
+		t, err := ParseEventType(strings.TrimSpace(name))
+		if err != nil {
+			return err
+		}
+		types = append(types, t)
+	}
+
+	for {
+		err := client.SubscribeEvents(types, printEvent)
+		if !errors.Is(err, ErrNetwork) {
+			return fmt.Errorf("failed to listen for Nanoleaf events: %w", err)
+		}
+		fmt.Println("error: lost connection to Nanoleaf events:", err)
+		time.Sleep(eventReconnectDelay)
+	}
+}
+
+// printEvent prints each change in event on its own line.
+func printEvent(event Event) error {
+	now := time.Now().Format("15:04:05")
+	for _, item := range event.Items {
+		if event.Type == EventTouch {
+			fmt.Printf("%s  touch: panel %d %s\n", now, item.PanelID, item.Gesture)
+			continue
+		}
+		var value interface{}
+		err := json.Unmarshal(item.Value, &value)
+		if err != nil {
+			value = string(item.Value)
+		}
+		fmt.Printf("%s  %s: %s = %v\n", now, event.Type, event.AttrName(item), value)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// favoriteSectionPrefix prefixes the names of favorite config sections.
+const favoriteSectionPrefix = "fav "
+
+func doFavoriteCommand(client Client, cfg *ini.File, args []string) error {
+	args, err := parseStateFlags(&client, "fav", args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return usageError("usage: picoleaf fav [--fade <duration>] [<name>]")
+	}
+
+	if len(args) == 0 {
+		for _, name := range favoriteNames(cfg) {
+			fmt.Println(name)
+		}
+		return nil
+	}
+
+	fav, err := cfg.GetSection(favoriteSectionPrefix + args[0])
+	if err != nil {
+		return fmt.Errorf("unknown favorite: %v", args[0])
+	}
+
+	err = applyFavorite(client, fav)
+	if err != nil {
+		return fmt.Errorf("failed to apply favorite: %w", err)
+	}
+	return nil
+}
+
+// favoriteNames returns the names of the configured favorites, in config
+// order.
+func favoriteNames(cfg *ini.File) []string {
+	var names []string
+	for _, section := range cfg.Sections() {
+		if strings.HasPrefix(section.Name(), favoriteSectionPrefix) {
+			names = append(names, strings.TrimPrefix(section.Name(), favoriteSectionPrefix))
+		}
+	}
+	return names
+}
+
+// applyFavorite selects a favorite's effect and sets its brightness.
+func applyFavorite(client Client, fav *ini.Section) error {
+	if fav.HasKey("effect") {
+		err := client.SelectEffect(fav.Key("effect").String())
+		if err != nil {
+			return err
+		}
+	}
+
+	if fav.HasKey("brightness") {
+		brightness, err := fav.Key("brightness").Int()
+		if err != nil || brightness < 0 || brightness > 100 {
+			return validationErrorf("brightness must be an integer 0-100")
+		}
+		return client.SetBrightness(brightness)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// fft computes the discrete Fourier transform of x in place. len(x) must be
+// a power of two.
+func fft(x []complex128) {
+	n := len(x)
+
+	// Reorder by bit-reversed index.
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				a, b := x[start+k], w*x[start+k+size/2]
+				x[start+k], x[start+k+size/2] = a+b, a-b
+				w *= step
+			}
+		}
+	}
+}
+
+// magnitudeSpectrum returns the magnitudes of the first half of the
+// Fourier transform of samples, after applying a Hann window.
+func magnitudeSpectrum(samples []float64) []float64 {
+	n := len(samples)
+	x := make([]complex128, n)
+	for i, s := range samples {
+		window := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n-1))
+		x[i] = complex(s*window, 0)
+	}
+	fft(x)
+
+	magnitudes := make([]float64, n/2)
+	for i := range magnitudes {
+		magnitudes[i] = cmplx.Abs(x[i])
+	}
+	return magnitudes
+}
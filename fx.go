@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+func doFxCommand(client Client, colors *ini.Section, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf fx breathe [--color <color>] [--period <duration>] [--depth <0-1>]")
+	}
+
+	if len(args) < 1 {
+		return usage()
+	}
+
+	command := args[0]
+	switch command {
+	case "breathe":
+		fs := flag.NewFlagSet("breathe", flag.ContinueOnError)
+		fs.Usage = func() { fmt.Println(usage()) }
+		colorName := fs.String("color", "white", "Color to breathe")
+		period := fs.Duration("period", 8*time.Second, "Time for one full breath")
+		depth := fs.Float64("depth", 0.6, "How far to dim at the bottom of each breath, 0-1")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+
+		if fs.NArg() != 0 {
+			return usage()
+		}
+		if *depth < 0 || *depth > 1 {
+			return errors.New("depth must be between 0 and 1")
+		}
+		if *period < 200*time.Millisecond {
+			return errors.New("period must be at least 200ms")
+		}
+
+		c, err := parseColor(*colorName, colors)
+		if err != nil {
+			return err
+		}
+
+		panelInfo, err := getPanelInfo(client)
+		if err != nil {
+			return err
+		}
+		animation := breatheAnimation(panelIDs(panelInfo), client.calibrated(c), *period, *depth)
+		err = client.DisplayEffect(Effect{
+			Type:     "custom",
+			AnimData: EncodeAnimData(animation),
+			Loop:     true,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to display effect: %w", err)
+		}
+	default:
+		return usage()
+	}
+	return nil
+}
+
+// panelIDs returns the IDs of all panels in the layout that emit light.
+func panelIDs(panelInfo *PanelInfo) []uint16 {
+	panels := lightPanels(panelInfo)
+	ids := make([]uint16, len(panels))
+	for i, panel := range panels {
+		ids[i] = uint16(panel.PanelID)
+	}
+	return ids
+}
+
+// breatheAnimation builds a looping animation that fades every panel between
+// a color and a dimmed version of it. The device plays it on its own.
+func breatheAnimation(ids []uint16, c RGB, period time.Duration, depth float64) []PanelAnimation {
+	dim := func(v uint8) uint8 {
+		return uint8(math.Round(float64(v) * (1 - depth)))
+	}
+
+	// Transition times are in tenths of a second.
+	half := uint16(period / 2 / (100 * time.Millisecond))
+	frames := []PanelFrame{
+		{Red: c.Red, Green: c.Green, Blue: c.Blue, TransitionTime: half},
+		{Red: dim(c.Red), Green: dim(c.Green), Blue: dim(c.Blue), TransitionTime: half},
+	}
+
+	animation := make([]PanelAnimation, len(ids))
+	for i, id := range ids {
+		animation[i] = PanelAnimation{PanelID: id, Frames: frames}
+	}
+	return animation
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+)
+
+// generatorPanel is a panel as seen by a generator, with its position
+// scaled to the layout: x from 0 (left) to 1 (right) and y from 0 (bottom)
+// to 1 (top).
+type generatorPanel struct {
+	ID uint16
+	X  float64
+	Y  float64
+}
+
+// Generator renders frames of a procedural animation.
+type Generator interface {
+	// Frame returns each panel's color t seconds into the animation.
+	// Frames are requested in order.
+	Frame(t float64) map[uint16]RGB
+}
+
+// generators are the procedural animations available to `picoleaf anim`,
+// by name. Each is created for a set of panels and a palette, which may be
+// empty to use the generator's default colors.
+var generators = map[string]func(panels []generatorPanel, palette []RGB, rng *rand.Rand) Generator{
+	"fire":    newFireGenerator,
+	"plasma":  newPlasmaGenerator,
+	"rainbow": newRainbowGenerator,
+	"sparkle": newSparkleGenerator,
+}
+
+// generatorPanels returns the panels in a layout for generators.
+func generatorPanels(polygons map[int][]point) []generatorPanel {
+	min, max := bounds(polygons)
+	scale := func(v, lo, hi float64) float64 {
+		if hi <= lo {
+			return 0.5
+		}
+		return (v - lo) / (hi - lo)
+	}
+
+	var panels []generatorPanel
+	for _, id := range sortedPanelIDs(polygons) {
+		c := centroid(polygons[id])
+		panels = append(panels, generatorPanel{
+			ID: uint16(id),
+			X:  scale(c.X, min.X, max.X),
+			Y:  scale(c.Y, min.Y, max.Y),
+		})
+	}
+	return panels
+}
+
+// gradient returns the color f of the way along a palette, from 0 to 1.
+// Without a palette, it goes around the hue wheel.
+func gradient(palette []RGB, f float64) RGB {
+	f = math.Max(0, math.Min(1, f))
+	if len(palette) == 0 {
+		r, g, b := hsvToRGB(int(f*360), 100, 100)
+		return RGB{uint8(r), uint8(g), uint8(b)}
+	}
+	if len(palette) == 1 {
+		return palette[0]
+	}
+
+	pos := f * float64(len(palette)-1)
+	i := int(pos)
+	if i >= len(palette)-1 {
+		return palette[len(palette)-1]
+	}
+	return blend(palette[i], palette[i+1], pos-float64(i))
+}
+
+// rainbowGenerator sweeps colors across the layout from left to right.
+type rainbowGenerator struct {
+	panels  []generatorPanel
+	palette []RGB
+}
+
+func newRainbowGenerator(panels []generatorPanel, palette []RGB, rng *rand.Rand) Generator {
+	return rainbowGenerator{panels, palette}
+}
+
+func (g rainbowGenerator) Frame(t float64) map[uint16]RGB {
+	colors := make(map[uint16]RGB, len(g.panels))
+	for _, p := range g.panels {
+		f := math.Mod(p.X*0.5+t*0.2, 1)
+		if len(g.palette) > 0 {
+			// Go there and back so the palette wraps smoothly.
+			f = 1 - math.Abs(2*f-1)
+		}
+		colors[p.ID] = gradient(g.palette, f)
+	}
+	return colors
+}
+
+// plasmaGenerator renders overlapping sine waves, the classic demo effect.
+type plasmaGenerator struct {
+	panels  []generatorPanel
+	palette []RGB
+}
+
+func newPlasmaGenerator(panels []generatorPanel, palette []RGB, rng *rand.Rand) Generator {
+	return plasmaGenerator{panels, palette}
+}
+
+func (g plasmaGenerator) Frame(t float64) map[uint16]RGB {
+	colors := make(map[uint16]RGB, len(g.panels))
+	for _, p := range g.panels {
+		v := math.Sin(p.X*6+t) +
+			math.Sin(p.Y*6+t*1.3) +
+			math.Sin((p.X+p.Y)*4+t*0.7) +
+			math.Sin(math.Hypot(p.X-0.5, p.Y-0.5)*8-t)
+		// v is between -4 and 4.
+		colors[p.ID] = gradient(g.palette, (v+4)/8)
+	}
+	return colors
+}
+
+// defaultFirePalette runs from embers to flames.
+var defaultFirePalette = []RGB{{0, 0, 0}, {180, 20, 0}, {255, 90, 0}, {255, 180, 20}, {255, 230, 120}}
+
+// fireGenerator flickers hot at the bottom of the layout and cooler at the
+// top.
+type fireGenerator struct {
+	panels  []generatorPanel
+	palette []RGB
+	rng     *rand.Rand
+	heat    map[uint16]float64
+}
+
+func newFireGenerator(panels []generatorPanel, palette []RGB, rng *rand.Rand) Generator {
+	if len(palette) == 0 {
+		palette = defaultFirePalette
+	}
+	return &fireGenerator{panels: panels, palette: palette, rng: rng, heat: make(map[uint16]float64)}
+}
+
+func (g *fireGenerator) Frame(t float64) map[uint16]RGB {
+	colors := make(map[uint16]RGB, len(g.panels))
+	for _, p := range g.panels {
+		target := (1-p.Y)*0.8 + g.rng.Float64()*0.4
+		g.heat[p.ID] += (target - g.heat[p.ID]) * 0.5
+		colors[p.ID] = gradient(g.palette, g.heat[p.ID])
+	}
+	return colors
+}
+
+// sparkleGenerator lights random panels, which then fade out.
+type sparkleGenerator struct {
+	panels  []generatorPanel
+	palette []RGB
+	rng     *rand.Rand
+	level   map[uint16]float64
+	color   map[uint16]RGB
+	last    float64
+}
+
+func newSparkleGenerator(panels []generatorPanel, palette []RGB, rng *rand.Rand) Generator {
+	if len(palette) == 0 {
+		palette = []RGB{{255, 255, 255}}
+	}
+	return &sparkleGenerator{
+		panels:  panels,
+		palette: palette,
+		rng:     rng,
+		level:   make(map[uint16]float64),
+		color:   make(map[uint16]RGB),
+	}
+}
+
+func (g *sparkleGenerator) Frame(t float64) map[uint16]RGB {
+	elapsed := t - g.last
+	g.last = t
+
+	colors := make(map[uint16]RGB, len(g.panels))
+	for _, p := range g.panels {
+		// Each panel sparkles about once every five seconds, and fades
+		// over one.
+		g.level[p.ID] = math.Max(0, g.level[p.ID]-elapsed)
+		if g.rng.Float64() < elapsed/5 {
+			g.level[p.ID] = 1
+			g.color[p.ID] = g.palette[g.rng.Intn(len(g.palette))]
+		}
+		colors[p.ID] = blend(RGB{}, g.color[p.ID], g.level[p.ID])
+	}
+	return colors
+}
@@ -0,0 +1,148 @@
+package main
+
+import "math"
+
+// point is a position in layout coordinates, with y increasing upwards.
+type point struct {
+	X float64
+	Y float64
+}
+
+// shapeGeometry describes how a panel shape type is drawn.
+type shapeGeometry struct {
+	Sides      int
+	SideLength float64
+}
+
+// shapeGeometries maps panel shape types to their geometry. Shapes without
+// an entry (controllers, connectors) are not drawn.
+var shapeGeometries = map[int]shapeGeometry{
+	0:  {3, 150}, // Light Panels triangle
+	2:  {4, 100}, // Canvas square
+	3:  {4, 100}, // Canvas control square (primary)
+	4:  {4, 100}, // Canvas control square (passive)
+	7:  {6, 67},  // Shapes hexagon
+	8:  {3, 134}, // Shapes triangle
+	9:  {3, 67},  // Shapes mini triangle
+	14: {6, 134}, // Elements hexagon
+	15: {6, 58},  // Elements hexagon corner
+	17: {2, 154}, // Lines
+	18: {2, 77},  // Lines (single zone)
+}
+
+// lineWidth is the drawn width of line-shaped panels.
+const lineWidth = 10.0
+
+// panelPolygon returns the outline of a panel, or nil if the shape isn't
+// drawable. Triangles with orientation 0 point up.
+func panelPolygon(panel PanelPosition) []point {
+	geometry, ok := shapeGeometries[panel.ShapeType]
+	if !ok {
+		return nil
+	}
+
+	center := point{float64(panel.X), float64(panel.Y)}
+	rotation := float64(panel.O) * math.Pi / 180
+
+	if geometry.Sides == 2 {
+		half := geometry.SideLength / 2
+		w := lineWidth / 2
+		corners := []point{{-half, -w}, {half, -w}, {half, w}, {-half, w}}
+		for i, c := range corners {
+			corners[i] = rotate(c, point{}, rotation)
+			corners[i].X += center.X
+			corners[i].Y += center.Y
+		}
+		return corners
+	}
+
+	n := geometry.Sides
+	radius := geometry.SideLength / (2 * math.Sin(math.Pi/float64(n)))
+	start := math.Pi / 2
+	if n%2 == 0 {
+		start += math.Pi / float64(n)
+	}
+
+	vertices := make([]point, n)
+	for i := range vertices {
+		angle := start + rotation + 2*math.Pi*float64(i)/float64(n)
+		vertices[i] = point{
+			X: center.X + radius*math.Cos(angle),
+			Y: center.Y + radius*math.Sin(angle),
+		}
+	}
+	return vertices
+}
+
+// layoutPolygons returns the outline of every drawable panel, keyed by
+// panel ID, with the global orientation applied about the layout's center.
+func layoutPolygons(panelInfo *PanelInfo) map[int][]point {
+	polygons := make(map[int][]point)
+	for _, panel := range panelInfo.PanelLayout.Layout.PositionData {
+		if polygon := panelPolygon(panel); polygon != nil {
+			polygons[panel.PanelID] = polygon
+		}
+	}
+
+	rotation := float64(panelInfo.PanelLayout.GlobalOrientation.Value) * math.Pi / 180
+	if rotation != 0 {
+		min, max := bounds(polygons)
+		center := point{(min.X + max.X) / 2, (min.Y + max.Y) / 2}
+		for _, polygon := range polygons {
+			for i, p := range polygon {
+				polygon[i] = rotate(p, center, rotation)
+			}
+		}
+	}
+	return polygons
+}
+
+// rotate rotates a point counterclockwise about a center.
+func rotate(p point, center point, radians float64) point {
+	sin, cos := math.Sincos(radians)
+	dx, dy := p.X-center.X, p.Y-center.Y
+	return point{
+		X: center.X + dx*cos - dy*sin,
+		Y: center.Y + dx*sin + dy*cos,
+	}
+}
+
+// bounds returns the corners of the bounding box of a set of polygons.
+func bounds(polygons map[int][]point) (point, point) {
+	min := point{math.Inf(1), math.Inf(1)}
+	max := point{math.Inf(-1), math.Inf(-1)}
+	for _, polygon := range polygons {
+		for _, p := range polygon {
+			min.X = math.Min(min.X, p.X)
+			min.Y = math.Min(min.Y, p.Y)
+			max.X = math.Max(max.X, p.X)
+			max.Y = math.Max(max.Y, p.Y)
+		}
+	}
+	return min, max
+}
+
+// centroid returns the average of a polygon's vertices.
+func centroid(polygon []point) point {
+	var c point
+	for _, p := range polygon {
+		c.X += p.X
+		c.Y += p.Y
+	}
+	n := float64(len(polygon))
+	return point{c.X / n, c.Y / n}
+}
+
+// contains reports whether a point lies inside a polygon.
+func contains(polygon []point, p point) bool {
+	inside := false
+	j := len(polygon) - 1
+	for i := range polygon {
+		a, b := polygon[i], polygon[j]
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < (b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+		j = i
+	}
+	return inside
+}
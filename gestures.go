@@ -0,0 +1,137 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// gestureAction is a change to the lights bound to a touch gesture in the
+// [gestures] section. Actions run in-process, so they work without any
+// other software running.
+type gestureAction func(client Client) error
+
+// parseGestureActions parses every action in the [gestures] section, keyed
+// like the section.
+func parseGestureActions(cfg *ini.File) (map[string]gestureAction, error) {
+	actions := map[string]gestureAction{}
+	for _, key := range cfg.Section("gestures").Keys() {
+		action, err := parseGestureAction(cfg, key.String())
+		if err != nil {
+			return nil, validationErrorf("%s: %v", key.Name(), err)
+		}
+		actions[key.Name()] = action
+	}
+	return actions, nil
+}
+
+// parseGestureAction parses an action: on, off, toggle, brightness, hue or
+// temp with a value or a +/- adjustment, or effect, fav, preset or scene
+// with a name.
+func parseGestureAction(cfg *ini.File, spec string) (gestureAction, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return nil, validationErrorf("empty action")
+	}
+	command, arg := fields[0], strings.Join(fields[1:], " ")
+
+	switch command {
+	case "on", "off", "toggle":
+		if arg != "" {
+			return nil, validationErrorf("%s takes no argument", command)
+		}
+		return func(client Client) error {
+			on := command == "on"
+			if command == "toggle" {
+				panelInfo, err := client.GetPanelInfo()
+				if err != nil {
+					return err
+				}
+				on = !panelInfo.State.On.Value
+			}
+			if on {
+				return client.On()
+			}
+			return client.Off()
+		}, nil
+	case "brightness":
+		return adjustAction(arg, 0, 100, false, func(s State) int { return s.Brightness.Value }, Client.SetBrightness)
+	case "hue":
+		return adjustAction(arg, 0, 359, true, func(s State) int { return s.Hue.Value }, Client.SetHue)
+	case "temp", "ct":
+		return adjustAction(arg, 1200, 6500, false, func(s State) int { return s.ColorTemperature.Value }, Client.SetColorTemperature)
+	case "effect":
+		if arg == "" {
+			return nil, validationErrorf("effect needs a name")
+		}
+		return func(client Client) error {
+			return client.SelectEffect(arg)
+		}, nil
+	case "fav":
+		fav, err := cfg.GetSection(favoriteSectionPrefix + arg)
+		if err != nil {
+			return nil, validationErrorf("unknown favorite: %s", arg)
+		}
+		return func(client Client) error {
+			return applyFavorite(client, fav)
+		}, nil
+	case "preset":
+		preset, err := cfg.GetSection(presetSectionPrefix + arg)
+		if err != nil {
+			return nil, validationErrorf("unknown preset: %s", arg)
+		}
+		return func(client Client) error {
+			return applyPreset(client, preset, cfg.Section("colors"))
+		}, nil
+	case "scene":
+		return func(client Client) error {
+			scenes, err := LoadScenes()
+			if err != nil {
+				return err
+			}
+			scene, ok := scenes[arg]
+			if !ok {
+				return validationErrorf("unknown scene: %s", arg)
+			}
+			return applyScene(cfg, scene, client.Duration)
+		}, nil
+	}
+	return nil, validationErrorf("unknown action: %s", command)
+}
+
+// adjustAction returns an action that sets a property to arg, or adjusts it
+// by arg if arg starts with + or -. Adjustments are clamped to min-max, or
+// wrap around if wrap is set.
+func adjustAction(arg string, min, max int, wrap bool, get func(State) int, set func(Client, int) error) (gestureAction, error) {
+	value, err := strconv.Atoi(arg)
+	if err != nil {
+		return nil, validationErrorf("value must be an integer, optionally with + or -")
+	}
+	relative := strings.HasPrefix(arg, "+") || strings.HasPrefix(arg, "-")
+	if !relative && (value < min || value > max) {
+		return nil, validationErrorf("value must be %d-%d", min, max)
+	}
+
+	return func(client Client) error {
+		if !relative {
+			return set(client, value)
+		}
+
+		panelInfo, err := client.GetPanelInfo()
+		if err != nil {
+			return err
+		}
+		v := get(panelInfo.State) + value
+		switch {
+		case wrap:
+			span := max - min + 1
+			v = min + ((v-min)%span+span)%span
+		case v < min:
+			v = min
+		case v > max:
+			v = max
+		}
+		return set(client, v)
+	}, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"math"
+	"os"
+	"time"
+)
+
+// minGIFDelay is the shortest frame delay honored from a GIF. Like
+// browsers, shorter delays are treated as 100ms.
+const minGIFDelay = 20 * time.Millisecond
+
+func doGIFCommand(client Client, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf gif <file> [--fps <fps>]")
+	}
+
+	fs := flag.NewFlagSet("gif", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	fps := fs.Float64("fps", 0, "Frames per second, instead of the GIF's frame delays")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 || *fps < 0 {
+		return usage()
+	}
+
+	f, err := os.Open(positional[0])
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	g, err := gif.DecodeAll(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to decode GIF: %w", err)
+	}
+
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	polygons := layoutPolygons(panelInfo)
+	if len(polygons) == 0 {
+		return errors.New("no panels in layout")
+	}
+
+	frames := gifFrames(g)
+	colors := make([]map[uint16]RGB, len(frames))
+	delays := make([]time.Duration, len(frames))
+	for i, frame := range frames {
+		colors[i] = samplePanelCentroids(frame, polygons)
+
+		delays[i] = time.Duration(g.Delay[i]) * 10 * time.Millisecond
+		if delays[i] < minGIFDelay {
+			delays[i] = 100 * time.Millisecond
+		}
+		if *fps > 0 {
+			delays[i] = time.Duration(float64(time.Second) / *fps)
+		}
+	}
+
+	// LoopCount is 0 to loop forever, -1 to play once, or the number of
+	// times to repeat after the first.
+	plays := g.LoopCount + 1
+	if g.LoopCount == 0 {
+		plays = 0
+	}
+
+	i, play := 0, 0
+	return streamFrames(client, func() ([]SetPanelColor, time.Duration, bool, error) {
+		if i == len(frames) {
+			i, play = 0, play+1
+		}
+		if plays != 0 && play >= plays {
+			return nil, 0, false, nil
+		}
+		i++
+		return panelColorFrame(colors[i-1], delays[i-1]), delays[i-1], true, nil
+	})
+}
+
+// panelColorFrame returns a frame setting panels to colors, transitioning
+// over the given duration.
+func panelColorFrame(colors map[uint16]RGB, transition time.Duration) []SetPanelColor {
+	// Transition times are in tenths of a second.
+	tenths := uint16(math.Round(transition.Seconds() * 10))
+	frame := make([]SetPanelColor, 0, len(colors))
+	for id, c := range colors {
+		frame = append(frame, SetPanelColor{PanelID: id, Red: c.Red, Green: c.Green, Blue: c.Blue, TransitionTime: tenths})
+	}
+	return frame
+}
+
+// gifFrames composites the frames of a GIF, which may each cover only part
+// of the image, into full images.
+func gifFrames(g *gif.GIF) []image.Image {
+	rect := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	if rect.Empty() && len(g.Image) > 0 {
+		rect = g.Image[0].Bounds()
+	}
+
+	canvas := image.NewRGBA(rect)
+	frames := make([]image.Image, len(g.Image))
+	for i, img := range g.Image {
+		var saved *image.RGBA
+		disposal := byte(0)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		if disposal == gif.DisposalPrevious {
+			saved = image.NewRGBA(rect)
+			draw.Draw(saved, rect, canvas, rect.Min, draw.Src)
+		}
+
+		draw.Draw(canvas, img.Bounds(), img, img.Bounds().Min, draw.Over)
+		frame := image.NewRGBA(rect)
+		draw.Draw(frame, rect, canvas, rect.Min, draw.Src)
+		frames[i] = frame
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, img.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = saved
+		}
+	}
+	return frames
+}
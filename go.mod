@@ -2,4 +2,14 @@ module github.com/paulrosania/picoleaf
 
 go 1.16
 
-require gopkg.in/ini.v1 v1.62.0 // indirect
+require (
+	github.com/gdamore/tcell/v2 v2.4.0
+	go.opentelemetry.io/otel v1.7.0
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.7.0
+	go.opentelemetry.io/otel/sdk v1.7.0
+	go.opentelemetry.io/otel/trace v1.7.0
+	golang.org/x/net v0.0.0-20210405180319-a5a99cb37ef4
+	google.golang.org/grpc v1.46.2
+	google.golang.org/protobuf v1.28.1
+	gopkg.in/ini.v1 v1.62.0
+)
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/paulrosania/picoleaf/picoleafpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grpcServer implements the gRPC service in picoleafpb on top of the REST
+// server's devices.
+type grpcServer struct {
+	picoleafpb.UnimplementedPicoleafServer
+	s *server
+}
+
+// serveGRPC serves the gRPC API on address until it fails.
+func (s *server) serveGRPC(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	g := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+			ctx, span := traceGRPC(ctx, info.FullMethod)
+			defer func() { endSpan(span, err) }()
+			if err := s.authorizeGRPC(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+			ctx, span := traceGRPC(ss.Context(), info.FullMethod)
+			defer func() { endSpan(span, err) }()
+			if err := s.authorizeGRPC(ctx); err != nil {
+				return err
+			}
+			return handler(srv, tracedServerStream{ss, ctx})
+		}),
+	)
+	picoleafpb.RegisterPicoleafServer(g, grpcServer{s: s})
+	return g.Serve(listener)
+}
+
+// authorizeGRPC checks a call's "authorization" metadata for the server's
+// bearer token, if it has one.
+func (s *server) authorizeGRPC(ctx context.Context) error {
+	if s.token == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, auth := range md.Get("authorization") {
+		if subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), []byte(s.token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "unauthorized")
+}
+
+// tracedServerStream is a gRPC stream whose context carries the call's span.
+type tracedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ss tracedServerStream) Context() context.Context {
+	return ss.ctx
+}
+
+// device returns the client for a named device, tracing its requests as
+// part of the call in ctx.
+func (g grpcServer) device(ctx context.Context, name string) (Client, error) {
+	client, ok := g.s.devices[name]
+	if !ok {
+		return client, status.Errorf(codes.NotFound, "unknown device: %s", name)
+	}
+	client.Context = ctx
+	return client, nil
+}
+
+// grpcError converts an error from a Client to a gRPC status, without
+// details that would reveal access tokens.
+func grpcError(err error) error {
+	var apiErr *APIError
+	switch {
+	case errors.Is(err, ErrValidation):
+		return status.Error(codes.InvalidArgument, publicError(err))
+	case errors.Is(err, ErrAuth):
+		return status.Error(codes.PermissionDenied, publicError(err))
+	case errors.As(err, &apiErr) && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500:
+		// The device rejected the request itself, so retrying won't help.
+		return status.Error(codes.FailedPrecondition, publicError(err))
+	default:
+		return status.Error(codes.Unavailable, publicError(err))
+	}
+}
+
+func (g grpcServer) ListDevices(ctx context.Context, req *picoleafpb.ListDevicesRequest) (*picoleafpb.ListDevicesResponse, error) {
+	var names []string
+	for name := range g.s.devices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return &picoleafpb.ListDevicesResponse{Devices: names}, nil
+}
+
+func (g grpcServer) GetState(ctx context.Context, req *picoleafpb.GetStateRequest) (*picoleafpb.DeviceState, error) {
+	client, err := g.device(ctx, req.Device)
+	if err != nil {
+		return nil, err
+	}
+	panelInfo, err := client.GetPanelInfo()
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &picoleafpb.DeviceState{
+		Device:     req.Device,
+		Model:      panelInfo.Model,
+		On:         panelInfo.State.On.Value,
+		Brightness: int32(panelInfo.State.Brightness.Value),
+		ColorMode:  panelInfo.State.ColorMode,
+		Hue:        int32(panelInfo.State.Hue.Value),
+		Sat:        int32(panelInfo.State.Saturation.Value),
+		Ct:         int32(panelInfo.State.ColorTemperature.Value),
+		Effect:     panelInfo.Effects.Selected,
+	}, nil
+}
+
+func (g grpcServer) SetState(ctx context.Context, req *picoleafpb.SetStateRequest) (*picoleafpb.DeviceState, error) {
+	client, err := g.device(ctx, req.Device)
+	if err != nil {
+		return nil, err
+	}
+	if req.Color != nil && (req.Hue != nil || req.Sat != nil || req.Ct != nil) {
+		return nil, status.Error(codes.InvalidArgument, "color can't be combined with hue, sat or ct")
+	}
+
+	var state State
+	if req.Color != nil {
+		c, err := parseColor(*req.Color, g.s.cfg.Section("colors"))
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		state = client.rgbState(int(c.Red), int(c.Green), int(c.Blue))
+	}
+	if req.On != nil {
+		state.On = &OnProperty{*req.On}
+	}
+	if req.Brightness != nil {
+		if *req.Brightness < 0 || *req.Brightness > 100 {
+			return nil, status.Error(codes.InvalidArgument, "brightness must be 0-100")
+		}
+		state.Brightness = &BrightnessProperty{Value: int(*req.Brightness)}
+	}
+	if req.Hue != nil {
+		if *req.Hue < 0 || *req.Hue > 360 {
+			return nil, status.Error(codes.InvalidArgument, "hue must be 0-360")
+		}
+		state.Hue = &HueProperty{Value: int(*req.Hue)}
+	}
+	if req.Sat != nil {
+		if *req.Sat < 0 || *req.Sat > 100 {
+			return nil, status.Error(codes.InvalidArgument, "sat must be 0-100")
+		}
+		state.Saturation = &SaturationProperty{Value: int(*req.Sat)}
+	}
+	if req.Ct != nil {
+		if *req.Ct <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "ct must be a positive integer")
+		}
+		panelInfo, err := client.GetPanelInfo()
+		if err != nil {
+			return nil, grpcError(err)
+		}
+		ct := panelInfo.State.ColorTemperature
+		if ct.Min != nil && int(*req.Ct) < *ct.Min {
+			return nil, status.Errorf(codes.InvalidArgument, "ct must be at least %d", *ct.Min)
+		}
+		if ct.Max != nil && int(*req.Ct) > *ct.Max {
+			return nil, status.Errorf(codes.InvalidArgument, "ct must be at most %d", *ct.Max)
+		}
+		state.ColorTemperature = &ColorTemperatureProperty{Value: int(*req.Ct)}
+	}
+
+	client.Duration = time.Duration(req.DurationMs) * time.Millisecond
+	err = client.SetState(state)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return g.GetState(ctx, &picoleafpb.GetStateRequest{Device: req.Device})
+}
+
+func (g grpcServer) ListEffects(ctx context.Context, req *picoleafpb.ListEffectsRequest) (*picoleafpb.ListEffectsResponse, error) {
+	client, err := g.device(ctx, req.Device)
+	if err != nil {
+		return nil, err
+	}
+	panelInfo, err := client.GetPanelInfo()
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &picoleafpb.ListEffectsResponse{
+		Selected: panelInfo.Effects.Selected,
+		Effects:  panelInfo.Effects.List,
+	}, nil
+}
+
+func (g grpcServer) SelectEffect(ctx context.Context, req *picoleafpb.SelectEffectRequest) (*picoleafpb.SelectEffectResponse, error) {
+	client, err := g.device(ctx, req.Device)
+	if err != nil {
+		return nil, err
+	}
+	err = client.SelectEffect(req.Effect)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &picoleafpb.SelectEffectResponse{}, nil
+}
+
+func (g grpcServer) StreamFrames(ss picoleafpb.Picoleaf_StreamFramesServer) error {
+	req, err := ss.Recv()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	client, err := g.device(ss.Context(), req.Device)
+	if err != nil {
+		return err
+	}
+
+	// Snapshots restore solid colors too, which can't be selected as
+	// effects.
+	snapshot, err := client.Snapshot()
+	if err != nil {
+		return grpcError(err)
+	}
+	stream, err := client.OpenStream()
+	if err != nil {
+		return grpcError(err)
+	}
+	defer stream.Close()
+	defer client.ApplySnapshot(*snapshot)
+
+	var frame uint64
+	for {
+		err = checkFrame(req)
+		if err != nil {
+			return err
+		}
+		for _, panel := range req.Panels {
+			stream.SetPanel(uint16(panel.PanelId), uint8(panel.Red), uint8(panel.Green), uint8(panel.Blue), uint16(panel.Transition))
+		}
+		err = stream.Flush()
+		if err != nil {
+			return grpcError(err)
+		}
+		frame++
+		err = ss.Send(&picoleafpb.StreamFramesResponse{Frame: frame})
+		if err != nil {
+			return err
+		}
+
+		req, err = ss.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// checkFrame checks that a frame's values fit the stream's fields, instead
+// of letting them wrap around.
+func checkFrame(req *picoleafpb.StreamFramesRequest) error {
+	for _, panel := range req.Panels {
+		if panel.Red > 255 || panel.Green > 255 || panel.Blue > 255 {
+			return status.Error(codes.InvalidArgument, "red, green and blue must be 0-255")
+		}
+		if panel.PanelId > 65535 || panel.Transition > 65535 {
+			return status.Error(codes.InvalidArgument, "panel_id and transition must be 0-65535")
+		}
+	}
+	return nil
+}
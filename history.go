@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// historyLimit is the number of previous states kept per device.
+	historyLimit = 20
+
+	// historySessionGap is the idle time that separates one session of
+	// changes from the next.
+	historySessionGap = 30 * time.Minute
+)
+
+// HistoryEntry is the state of a device before a change.
+type HistoryEntry struct {
+	Time     time.Time `json:"time"`
+	Snapshot Snapshot  `json:"snapshot"`
+}
+
+// historyPath returns the path of the saved state history.
+func historyPath() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history.json"), nil
+}
+
+// LoadHistory returns the saved state history, oldest first, keyed by
+// configured device name.
+func LoadHistory() (map[string][]HistoryEntry, error) {
+	path, err := historyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	history := map[string][]HistoryEntry{}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &history)
+	return history, err
+}
+
+// SaveHistory replaces the saved state history.
+func SaveHistory(history map[string][]HistoryEntry) error {
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// changesState reports whether an API request changes what the Nanoleaf
+// shows, and so should be recorded in the history: a state change, an effect
+// selection, or an effect displayed directly. Previews and external control
+// end by themselves, and changes to the effects list can't be undone by a
+// snapshot, so they aren't recorded.
+func changesState(method string, path string, body []byte) bool {
+	if method == http.MethodGet {
+		return false
+	}
+
+	switch path {
+	case "state", "effects/select":
+		return true
+	case "effects":
+		var request struct {
+			Write struct {
+				Command  string `json:"command"`
+				AnimType string `json:"animType"`
+			} `json:"write"`
+		}
+		json.Unmarshal(body, &request)
+		return request.Write.Command == "display" && request.Write.AnimType != "extControl"
+	}
+	return false
+}
+
+// HistoryRecorder saves a device's state to the history before the first
+// change made by a command, so that undo reverts the whole command.
+type HistoryRecorder struct {
+	// Device is the configured device name, or "" for the default device.
+	Device string
+
+	once sync.Once
+}
+
+// record saves the device's current state, if it hasn't been saved yet.
+func (h *HistoryRecorder) record(client Client) {
+	h.once.Do(func() {
+		client.History = nil
+		recordHistory(client, h.Device)
+	})
+}
+
+// recordHistory saves the device's current state before a change. Failures
+// are only reported in verbose mode, so they never get in the way of the
+// change itself.
+func recordHistory(client Client, device string) {
+	err := func() error {
+		snapshot, err := client.Snapshot()
+		if err != nil {
+			return err
+		}
+		history, err := LoadHistory()
+		if err != nil {
+			return err
+		}
+
+		entries := append(history[device], HistoryEntry{Time: time.Now(), Snapshot: *snapshot})
+		if len(entries) > historyLimit {
+			entries = entries[len(entries)-historyLimit:]
+		}
+		history[device] = entries
+		return SaveHistory(history)
+	}()
+	if err != nil && client.Verbose {
+		fmt.Println("warning: failed to record state history:", err)
+	}
+}
+
+func loadHistory() (map[string][]HistoryEntry, error) {
+	history, err := LoadHistory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state history: %w", err)
+	}
+	return history, nil
+}
+
+// revertHistory applies the device's history entry at index i, and drops it
+// and everything after it.
+func revertHistory(client Client, device string, history map[string][]HistoryEntry, i int) error {
+	entries := history[device]
+	client.History = nil
+	err := client.ApplySnapshot(entries[i].Snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to restore state: %w", err)
+	}
+
+	history[device] = entries[:i]
+	err = SaveHistory(history)
+	if err != nil {
+		return fmt.Errorf("failed to save state history: %w", err)
+	}
+	return nil
+}
+
+func doUndoCommand(client Client, device string, args []string) error {
+	args, err := parseStateFlags(&client, "undo", args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return usageError("usage: picoleaf undo [--fade <duration>]")
+	}
+
+	history, err := loadHistory()
+	if err != nil {
+		return err
+	}
+	if len(history[device]) == 0 {
+		return errors.New("nothing to undo")
+	}
+	return revertHistory(client, device, history, len(history[device])-1)
+}
+
+func doRestoreCommand(client Client, device string, args []string) error {
+	args, err := parseStateFlags(&client, "restore", args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		return doBackupRestoreCommand(client, args[0])
+	}
+	if len(args) != 0 {
+		return usageError("usage: picoleaf restore [--fade <duration>] [<backup file>]")
+	}
+
+	history, err := loadHistory()
+	if err != nil {
+		return err
+	}
+	entries := history[device]
+	if len(entries) == 0 {
+		return errors.New("nothing to restore")
+	}
+
+	// Walk back to the first change of the most recent session.
+	i := len(entries) - 1
+	for i > 0 && entries[i].Time.Sub(entries[i-1].Time) < historySessionGap {
+		i--
+	}
+	return revertHistory(client, device, history, i)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+)
+
+func doImageCommand(client Client, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf image <file> [--stream]")
+	}
+
+	fs := flag.NewFlagSet("image", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	stream := fs.Bool("stream", false, "Stream the colors instead of displaying a static effect, until the next change")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return usage()
+	}
+
+	f, err := os.Open(positional[0])
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	img, _, err := image.Decode(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	polygons := layoutPolygons(panelInfo)
+	if len(polygons) == 0 {
+		return errors.New("no panels in layout")
+	}
+
+	colors := samplePanelAverages(img, polygons)
+	for id, c := range colors {
+		colors[id] = client.calibrated(c)
+	}
+
+	if *stream {
+		var frames []SetPanelColor
+		for _, id := range sortedPanelIDs(polygons) {
+			c := colors[uint16(id)]
+			frames = append(frames, SetPanelColor{PanelID: uint16(id), Red: c.Red, Green: c.Green, Blue: c.Blue})
+		}
+		err = client.SetCustomColors(frames)
+	} else {
+		err = setPanelColors(client, panelInfo, colors)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set panel colors: %w", err)
+	}
+	return nil
+}
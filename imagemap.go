@@ -0,0 +1,113 @@
+package main
+
+import (
+	"image"
+)
+
+// imageProjection maps layout coordinates onto an image stretched over the
+// layout's bounding box.
+type imageProjection struct {
+	min    point
+	max    point
+	bounds image.Rectangle
+}
+
+// newImageProjection returns a projection of an image with the given
+// bounds over a set of panel polygons.
+func newImageProjection(polygons map[int][]point, rect image.Rectangle) imageProjection {
+	min, max := bounds(polygons)
+	return imageProjection{min: min, max: max, bounds: rect}
+}
+
+// imagePoint returns the image pixel under a layout point. Layout y
+// increases upwards; image y increases downwards.
+func (p imageProjection) imagePoint(pt point) image.Point {
+	fx := 0.5
+	if p.max.X > p.min.X {
+		fx = (pt.X - p.min.X) / (p.max.X - p.min.X)
+	}
+	fy := 0.5
+	if p.max.Y > p.min.Y {
+		fy = (p.max.Y - pt.Y) / (p.max.Y - p.min.Y)
+	}
+
+	x := p.bounds.Min.X + int(fx*float64(p.bounds.Dx()))
+	y := p.bounds.Min.Y + int(fy*float64(p.bounds.Dy()))
+	if x >= p.bounds.Max.X {
+		x = p.bounds.Max.X - 1
+	}
+	if y >= p.bounds.Max.Y {
+		y = p.bounds.Max.Y - 1
+	}
+	return image.Point{x, y}
+}
+
+// layoutPoint returns the layout point under the center of an image pixel.
+func (p imageProjection) layoutPoint(x, y int) point {
+	fx := (float64(x-p.bounds.Min.X) + 0.5) / float64(p.bounds.Dx())
+	fy := (float64(y-p.bounds.Min.Y) + 0.5) / float64(p.bounds.Dy())
+	return point{
+		X: p.min.X + fx*(p.max.X-p.min.X),
+		Y: p.max.Y - fy*(p.max.Y-p.min.Y),
+	}
+}
+
+// samplePanelCentroids returns the color of the image under the centroid of
+// each panel, with the image stretched over the layout.
+func samplePanelCentroids(img image.Image, polygons map[int][]point) map[uint16]RGB {
+	projection := newImageProjection(polygons, img.Bounds())
+	colors := make(map[uint16]RGB, len(polygons))
+	for id, polygon := range polygons {
+		at := projection.imagePoint(centroid(polygon))
+		colors[uint16(id)] = rgbAt(img, at.X, at.Y)
+	}
+	return colors
+}
+
+// rgbAt returns the color of an image pixel, ignoring alpha.
+func rgbAt(img image.Image, x, y int) RGB {
+	r, g, b, _ := img.At(x, y).RGBA()
+	return RGB{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8)}
+}
+
+// samplePanelAverages returns the average color of the image over each
+// panel's area, with the image stretched over the layout. Panels too small
+// to cover a pixel center use the color under their centroid.
+func samplePanelAverages(img image.Image, polygons map[int][]point) map[uint16]RGB {
+	projection := newImageProjection(polygons, img.Bounds())
+	colors := make(map[uint16]RGB, len(polygons))
+	for id, polygon := range polygons {
+		var box image.Rectangle
+		for i, p := range polygon {
+			at := projection.imagePoint(p)
+			r := image.Rect(at.X, at.Y, at.X+1, at.Y+1)
+			if i == 0 {
+				box = r
+			} else {
+				box = box.Union(r)
+			}
+		}
+
+		var r, g, b, n int
+		for y := box.Min.Y; y < box.Max.Y; y++ {
+			for x := box.Min.X; x < box.Max.X; x++ {
+				if !contains(polygon, projection.layoutPoint(x, y)) {
+					continue
+				}
+				c := rgbAt(img, x, y)
+				r += int(c.Red)
+				g += int(c.Green)
+				b += int(c.Blue)
+				n++
+			}
+		}
+
+		if n == 0 {
+			at := projection.imagePoint(centroid(polygon))
+			colors[uint16(id)] = rgbAt(img, at.X, at.Y)
+			continue
+		}
+		colors[uint16(id)] = RGB{uint8(r / n), uint8(g / n), uint8(b / n)}
+	}
+	return colors
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"gopkg.in/ini.v1"
+)
+
+// Steps for the keyboard remote's arrow keys.
+const (
+	keysBrightnessStep = 5
+	keysHueStep        = 10
+)
+
+func doKeysCommand(client Client, cfg *ini.File, args []string) error {
+	if len(args) != 0 {
+		return usageError("usage: picoleaf keys")
+	}
+
+	favorites := favoriteNames(cfg)
+	if len(favorites) > 9 {
+		favorites = favorites[:9]
+	}
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	state := panelInfo.State
+
+	screen, err := tcell.NewScreen()
+	if err == nil {
+		err = screen.Init()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to start terminal UI: %w", err)
+	}
+	defer screen.Fini()
+
+	// The state is tracked locally rather than read back after every key,
+	// so holding a key down stays responsive.
+	on := state.On.Value
+	brightness := state.Brightness.Value
+	hue := state.Hue.Value
+	message := ""
+
+	for {
+		screen.Clear()
+		power := "off"
+		if on {
+			power = "on"
+		}
+		drawText(screen, 0, 0, tcell.StyleDefault.Bold(true), "Nanoleaf remote")
+		drawText(screen, 0, 2, tcell.StyleDefault, fmt.Sprintf("Power: %-3s  Brightness: %3d  Hue: %3d", power, brightness, hue))
+		for i, name := range favorites {
+			drawText(screen, 0, 4+i, tcell.StyleDefault, fmt.Sprintf("%d  %s", i+1, name))
+		}
+		y := 5 + len(favorites)
+		drawText(screen, 0, y, tcell.StyleDefault, message)
+		drawText(screen, 0, y+1, tcell.StyleDefault.Bold(true), "↑/↓: brightness  ←/→: hue  space: power  1-9: favorites  q: quit")
+		screen.Show()
+
+		ev, ok := screen.PollEvent().(*tcell.EventKey)
+		if !ok {
+			screen.Sync()
+			continue
+		}
+
+		message = ""
+		var err error
+		switch {
+		case ev.Key() == tcell.KeyEscape || ev.Key() == tcell.KeyCtrlC || ev.Rune() == 'q':
+			return nil
+		case ev.Key() == tcell.KeyUp, ev.Key() == tcell.KeyDown:
+			step := keysBrightnessStep
+			if ev.Key() == tcell.KeyDown {
+				step = -step
+			}
+			brightness += step
+			if brightness < 0 {
+				brightness = 0
+			} else if brightness > 100 {
+				brightness = 100
+			}
+			err = client.SetBrightness(brightness)
+			on = on || err == nil
+		case ev.Key() == tcell.KeyLeft, ev.Key() == tcell.KeyRight:
+			step := keysHueStep
+			if ev.Key() == tcell.KeyLeft {
+				step = -step
+			}
+			hue = (hue + step + 360) % 360
+			err = client.SetHue(hue)
+		case ev.Rune() == ' ':
+			if on {
+				err = client.Off()
+			} else {
+				err = client.On()
+			}
+			if err == nil {
+				on = !on
+			}
+		case ev.Rune() >= '1' && ev.Rune() <= '9':
+			i := int(ev.Rune() - '1')
+			if i >= len(favorites) {
+				continue
+			}
+			err = applyFavorite(client, cfg.Section(favoriteSectionPrefix+favorites[i]))
+			if err == nil {
+				message = "Applied " + favorites[i]
+				var panelInfo *PanelInfo
+				panelInfo, err = client.GetPanelInfo()
+				if err == nil {
+					on = panelInfo.State.On.Value
+					brightness = panelInfo.State.Brightness.Value
+					hue = panelInfo.State.Hue.Value
+				}
+			}
+		}
+		if err != nil {
+			message = "error: " + err.Error()
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultTerminalWidth is used when the terminal width is unknown.
+const defaultTerminalWidth = 80
+
+// defaultImageWidth is the width of exported PNG layouts, in pixels.
+const defaultImageWidth = 800
+
+func doLayoutCommand(client Client, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf layout [--width <columns>]\n       picoleaf layout [--colors] --svg <file>\n       picoleaf layout [--colors] [--width <pixels>] --png <file>")
+	}
+
+	fs := flag.NewFlagSet("layout", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	width := fs.Int("width", 0, "Width of the drawing in characters, or pixels for --png")
+	svgPath := fs.String("svg", "", "Write the layout to an SVG file")
+	pngPath := fs.String("png", "", "Write the layout to a PNG file")
+	withColors := fs.Bool("colors", false, "Fill panels with their current colors")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 || *width < 0 || (*svgPath != "" && *pngPath != "") {
+		return usage()
+	}
+
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	polygons := layoutPolygons(panelInfo)
+	if len(polygons) == 0 {
+		return errors.New("layout has no drawable panels")
+	}
+
+	if *svgPath == "" && *pngPath == "" {
+		if *width == 0 {
+			*width = terminalWidth()
+		}
+		for _, line := range renderLayoutASCII(polygons, *width) {
+			fmt.Println(line)
+		}
+		return nil
+	}
+
+	colors := make(map[uint16]RGB)
+	if *withColors {
+		colors = currentPanelColors(client, panelInfo)
+	}
+
+	path := *svgPath
+	if path == "" {
+		path = *pngPath
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	if *svgPath != "" {
+		err = writeLayoutSVG(f, polygons, colors)
+	} else {
+		if *width == 0 {
+			*width = defaultImageWidth
+		}
+		err = writeLayoutPNG(f, polygons, colors, *width)
+	}
+	if err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// terminalWidth returns the width of the terminal according to $COLUMNS.
+func terminalWidth() int {
+	width, err := strconv.Atoi(os.Getenv("COLUMNS"))
+	if err != nil || width <= 0 {
+		return defaultTerminalWidth
+	}
+	return width
+}
+
+// Shape types that don't emit light, such as controllers and connectors.
+var nonLightShapeTypes = map[int]bool{
+	1:  true, // Rhythm module
+	5:  true, // Power supply
+	12: true, // Shapes controller
+	16: true, // Lines connector
+	19: true, // Controller cap
+	20: true, // Power connector
+}
+
+// lightPanels returns the panels in the layout that emit light.
+func lightPanels(panelInfo *PanelInfo) []PanelPosition {
+	var panels []PanelPosition
+	for _, panel := range panelInfo.PanelLayout.Layout.PositionData {
+		if !nonLightShapeTypes[panel.ShapeType] {
+			panels = append(panels, panel)
+		}
+	}
+	return panels
+}
+
+// panelsLeftToRight returns a copy of the panels ordered by x, then by y.
+func panelsLeftToRight(panels []PanelPosition) []PanelPosition {
+	sorted := make([]PanelPosition, len(panels))
+	copy(sorted, panels)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].X != sorted[j].X {
+			return sorted[i].X < sorted[j].X
+		}
+		return sorted[i].Y < sorted[j].Y
+	})
+	return sorted
+}
+
+// renderLayoutASCII draws the panel outlines as characters, labeling each
+// panel with its ID. Terminal cells are assumed to be twice as tall as they
+// are wide.
+func renderLayoutASCII(polygons map[int][]point, width int) []string {
+	if len(polygons) == 0 {
+		return nil
+	}
+
+	ids := make([]int, 0, len(polygons))
+	for id := range polygons {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	min, max := bounds(polygons)
+	scaleX := float64(width-1) / math.Max(max.X-min.X, 1)
+	scaleY := scaleX / 2
+	rows := int(math.Ceil((max.Y-min.Y)*scaleY)) + 1
+
+	// Find the panel under the center of each cell.
+	owners := make([][]int, rows)
+	for row := range owners {
+		owners[row] = make([]int, width)
+		for col := range owners[row] {
+			owners[row][col] = -1
+			p := point{
+				X: min.X + (float64(col)+0.5)/scaleX,
+				Y: max.Y - (float64(row)+0.5)/scaleY,
+			}
+			for _, id := range ids {
+				if contains(polygons[id], p) {
+					owners[row][col] = id
+					break
+				}
+			}
+		}
+	}
+
+	owner := func(row, col int) int {
+		if row < 0 || row >= rows || col < 0 || col >= width {
+			return -1
+		}
+		return owners[row][col]
+	}
+
+	grid := make([][]rune, rows)
+	for row := range grid {
+		grid[row] = make([]rune, width)
+		for col := range grid[row] {
+			id := owners[row][col]
+			edge := owner(row-1, col) != id || owner(row+1, col) != id ||
+				owner(row, col-1) != id || owner(row, col+1) != id
+			switch {
+			case id < 0:
+				grid[row][col] = ' '
+			case edge:
+				grid[row][col] = '.'
+			default:
+				grid[row][col] = ' '
+			}
+		}
+	}
+
+	for _, id := range ids {
+		c := centroid(polygons[id])
+		label := []rune(strconv.Itoa(id))
+		row := int((max.Y - c.Y) * scaleY)
+		col := int((c.X-min.X)*scaleX) - len(label)/2
+		if row < 0 || row >= rows {
+			continue
+		}
+		for i, r := range label {
+			if col+i >= 0 && col+i < width {
+				grid[row][col+i] = r
+			}
+		}
+	}
+
+	var lines []string
+	for row := range grid {
+		line := strings.TrimRight(string(grid[row]), " ")
+		if line == "" && len(lines) == 0 {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
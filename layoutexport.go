@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// layoutMargin is the space around exported layouts, in layout units.
+const layoutMargin = 20.0
+
+// defaultPanelColor fills panels whose color is unknown.
+var defaultPanelColor = RGB{220, 220, 220}
+
+// writeLayoutSVG draws the panels as an SVG image, labeled with their IDs.
+// Panels missing from colors are drawn in a neutral gray.
+func writeLayoutSVG(w io.Writer, polygons map[int][]point, colors map[uint16]RGB) error {
+	min, max := bounds(polygons)
+	width := max.X - min.X + 2*layoutMargin
+	height := max.Y - min.Y + 2*layoutMargin
+
+	// Layout y increases upwards; SVG y increases downwards.
+	toSVG := func(p point) point {
+		return point{p.X - min.X + layoutMargin, max.Y - p.Y + layoutMargin}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%.0f\" height=\"%.0f\" viewBox=\"0 0 %.0f %.0f\">\n", width, height, width, height)
+	for _, id := range sortedPanelIDs(polygons) {
+		c, ok := colors[uint16(id)]
+		if !ok {
+			c = defaultPanelColor
+		}
+
+		points := make([]string, len(polygons[id]))
+		for i, p := range polygons[id] {
+			p = toSVG(p)
+			points[i] = fmt.Sprintf("%.1f,%.1f", p.X, p.Y)
+		}
+		fmt.Fprintf(&b, "  <polygon points=\"%s\" fill=\"#%02x%02x%02x\" stroke=\"#333\" stroke-width=\"2\"/>\n", strings.Join(points, " "), c.Red, c.Green, c.Blue)
+
+		center := toSVG(centroid(polygons[id]))
+		fmt.Fprintf(&b, "  <text x=\"%.1f\" y=\"%.1f\" font-family=\"sans-serif\" font-size=\"14\" text-anchor=\"middle\" dominant-baseline=\"middle\">%d</text>\n", center.X, center.Y, id)
+	}
+	b.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeLayoutPNG draws the panels as a PNG image of the given width. Panel
+// IDs are not labeled.
+func writeLayoutPNG(w io.Writer, polygons map[int][]point, colors map[uint16]RGB, width int) error {
+	min, max := bounds(polygons)
+	scale := float64(width) / (max.X - min.X + 2*layoutMargin)
+	height := int(math.Ceil((max.Y - min.Y + 2*layoutMargin) * scale))
+
+	ids := sortedPanelIDs(polygons)
+	owners := make([]int, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			p := point{
+				X: min.X - layoutMargin + (float64(x)+0.5)/scale,
+				Y: max.Y + layoutMargin - (float64(y)+0.5)/scale,
+			}
+			owners[y*width+x] = -1
+			for _, id := range ids {
+				if contains(polygons[id], p) {
+					owners[y*width+x] = id
+					break
+				}
+			}
+		}
+	}
+
+	owner := func(x, y int) int {
+		if x < 0 || x >= width || y < 0 || y >= height {
+			return -1
+		}
+		return owners[y*width+x]
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			id := owner(x, y)
+			if id < 0 {
+				continue
+			}
+
+			c, ok := colors[uint16(id)]
+			if !ok {
+				c = defaultPanelColor
+			}
+			if owner(x-1, y) != id || owner(x+1, y) != id || owner(x, y-1) != id || owner(x, y+1) != id {
+				c = RGB{51, 51, 51}
+			}
+			img.Set(x, y, color.RGBA{c.Red, c.Green, c.Blue, 255})
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// sortedPanelIDs returns the panel IDs of a set of polygons in order.
+func sortedPanelIDs(polygons map[int][]point) []int {
+	ids := make([]int, 0, len(polygons))
+	for id := range polygons {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
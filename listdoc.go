@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// parseListDocument parses the small subset of YAML used by playlists and
+// rules files: top-level `key: value` pairs, passed to top as they're read,
+// and a list under listKey whose items are indented `key: value` pairs
+// starting with `- `. Comments start with `#`, and values may be quoted.
+func parseListDocument(r io.Reader, listKey string, top func(key string, value string) error) ([]map[string]string, error) {
+	var items []map[string]string
+	inList := false
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		fail := func(format string, args ...interface{}) ([]map[string]string, error) {
+			return nil, validationErrorf("line %d: %s", line, fmt.Sprintf(format, args...))
+		}
+
+		indented := text[0] == ' ' || text[0] == '\t'
+		item := strings.HasPrefix(trimmed, "- ")
+		if item {
+			trimmed = strings.TrimSpace(trimmed[2:])
+		}
+
+		i := strings.Index(trimmed, ":")
+		if i < 0 {
+			return fail("expected `key: value`")
+		}
+		key := strings.TrimSpace(trimmed[:i])
+		value := strings.TrimSpace(trimmed[i+1:])
+		if j := strings.Index(value, " #"); j >= 0 && !strings.HasPrefix(value, "\"") && !strings.HasPrefix(value, "'") {
+			value = strings.TrimSpace(value[:j])
+		}
+		value = unquote(value)
+
+		if !indented && !item {
+			inList = false
+			if key == listKey {
+				if value != "" {
+					return fail("expected a list of %s", listKey)
+				}
+				inList = true
+				continue
+			}
+			if err := top(key, value); err != nil {
+				return fail("%v", err)
+			}
+			continue
+		}
+
+		if !inList {
+			return fail("unexpected indentation")
+		}
+		if item {
+			items = append(items, map[string]string{})
+		} else if len(items) == 0 {
+			return fail("expected an item starting with `-`")
+		}
+		fields := items[len(items)-1]
+		if _, ok := fields[key]; ok {
+			return fail("duplicate key %q", key)
+		}
+		fields[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// unquote strips matching single or double quotes from a value.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -1,150 +1,835 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"math"
+	"net"
 	"os"
-	"os/user"
-	"path/filepath"
 	"strconv"
+	"strings"
+	"text/template"
+	"time"
 
 	"gopkg.in/ini.v1"
 )
 
 const defaultConfigFile = ".picoleafrc"
 
+// deviceSectionPrefix prefixes the names of per-device config sections.
+const deviceSectionPrefix = "device "
+
+// discoveryTimeout is how long to wait for SSDP responses.
+const discoveryTimeout = 5 * time.Second
+
+// scanTimeout is how long to wait for each host when scanning a subnet.
+const scanTimeout = 500 * time.Millisecond
+
 var verbose = flag.Bool("v", false, "Verbose")
+var device = flag.String("device", "", "Name of the configured device to control")
+var format = flag.String("format", "", "Go template for the output of read commands")
+var tracePath = flag.String("trace", "", "Record API requests to a JSON file")
+var recordPath = flag.String("record", "", "Record API requests and responses, with headers, to a HAR file")
+var queueChanges = flag.Bool("queue", false, "Queue changes if Nanoleaf is unreachable, and send them once it's back")
+var streamVersion = flag.String("stream-version", "", "External control protocol version (v1 or v2)")
 
-func usage() {
-	fmt.Println("usage: picoleaf [-v] <command>")
-	fmt.Println()
-	fmt.Println("Commands:")
-	fmt.Println()
-	fmt.Println("   on           Turn on Nanoleaf")
-	fmt.Println("   off          Turn off Nanoleaf")
-	fmt.Println()
-	fmt.Println("   effect       Control Nanoleaf effects")
-	fmt.Println("   panel        Control Nanoleaf panel")
-	fmt.Println()
-	fmt.Println("   hsl          Set Nanoleaf to the provided HSL")
-	fmt.Println("   rgb          Set Nanoleaf to the provided RGB")
-	fmt.Println("   temp         Set Nanoleaf to the provided color temperature")
-	fmt.Println("   brightness   Set Nanoleaf to the provided brightness")
-	fmt.Println()
-	os.Exit(1)
+func usage() error {
+	return usageError(`usage: picoleaf [-v] [-device <name>] [-format <template>] [-stream-version v1|v2] [-trace <file>] [-record <file>] [-queue] <command>
+
+Commands:
+
+   on           Turn on Nanoleaf
+   off          Turn off Nanoleaf
+   identify     Flash Nanoleaf panels
+
+   discover     Find Nanoleaf devices on the local network
+   pair         Pair with Nanoleaf and save an access token
+   doctor       Check the config file and connection for problems
+
+   effect       Control Nanoleaf effects
+   fav          Apply a favorite from the config file
+   preset       Apply a preset from the config file
+   scene        Save and apply snapshots of the Nanoleaf state
+   playlist     Step through scenes, presets, effects, and colors
+   fx           Play a generated effect
+   anim         Stream a generated animation to the panels
+   play         Stream a frame animation to the panels
+   image        Show an image on the panels
+   gif          Play an animated GIF on the panels
+   video        Play a video on the panels
+   text         Scroll text across the panels
+   clock        Show the time on the panels
+   weather      Show the current weather on the panels
+   music        Visualize audio from the microphone on the panels
+   notify       Flash a color, then restore the previous state
+   progress     Show a progress bar across the panels
+   panel        Control Nanoleaf panel
+   layout       Draw the Nanoleaf panel layout
+
+   keys         Control Nanoleaf with the keyboard, like a remote
+   tui          Show a dashboard to control Nanoleaf from the terminal
+   info         Print all Nanoleaf information
+   status       Print a summary of the Nanoleaf state
+   watch        Print changes to the Nanoleaf state as they happen
+   events       Print events from Nanoleaf, including touch gestures
+   touch-daemon Run commands when Nanoleaf panels are touched
+   rhythm       Show the Rhythm module or set its audio source
+   describe     Describe the Nanoleaf state in a sentence
+
+   circadian    Follow the sun with color temperature and brightness
+   autotemp     Follow a color temperature and brightness schedule
+   wake         Simulate a sunrise to wake up to
+   sleep        Dim Nanoleaf to off over a while
+   schedule     Run commands on a schedule
+   rules        Run automation rules triggered by events, times and webhooks
+   webhook      Run actions when webhooks are received
+   serve        Serve a REST API for controlling Nanoleaf devices
+
+   undo         Revert the last change
+   restore      Revert every change since the most recent session began,
+                or restore a backup
+   backup       Save the state, effects and orientation to a file
+   queue        List, send or clear changes queued with -queue
+
+   pick         Pick a color interactively and print its hex value
+   calibrate    Interactively match Nanoleaf colors to your screen
+   color        Set Nanoleaf to the provided color name or hex value
+   hsl          Set Nanoleaf to the provided HSL
+   rgb          Set Nanoleaf to the provided RGB
+   temp         Set Nanoleaf to the provided or named color temperature
+   brightness   Set Nanoleaf to the provided brightness
+
+   hue          Get or set Nanoleaf hue
+   sat          Get or set Nanoleaf saturation
+   ct           Get or set Nanoleaf color temperature (alias for temp)
+
+   raw          Send a request to the Nanoleaf API
+   run          Run picoleaf commands from a file
+`)
+}
+
+// usageError is returned by a command given the wrong arguments. It's the
+// command's usage, which is printed as is rather than as an error.
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// flagError is returned when a command's flags fail to parse. The flag set
+// has already reported it, along with the flags' usage.
+type flagError struct {
+	err error
+}
+
+func (e *flagError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the flag set's error.
+func (e *flagError) Unwrap() error {
+	return e.err
+}
+
+// printError prints an error returned by a command, unless it has already
+// been reported. prefix is added to error messages, like "line 3: ", and
+// flag errors are repeated with it so it's clear where they came from.
+func printError(err error, prefix string) {
+	var usage usageError
+	var flagErr *flagError
+	switch {
+	case errors.As(err, &usage):
+		fmt.Println(prefix + err.Error())
+	case errors.As(err, &flagErr) && prefix == "":
+	default:
+		fmt.Printf("error: %s%v\n", prefix, err)
+	}
 }
 
 func main() {
 	flag.Parse()
 
-	usr, err := user.Current()
+	err := run()
+	if err == nil || errors.Is(err, flag.ErrHelp) || reportQueued(err) {
+		return
+	}
+	printError(err, "")
+	var flagErr *flagError
+	if errors.As(err, &flagErr) {
+		os.Exit(2)
+	}
+	os.Exit(1)
+}
+
+// run loads the config and runs the command given on the command line.
+func run() error {
+	configFilePath, err := configPath()
 	if err != nil {
-		fmt.Println("error: failed to fetch current user:", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to find config file: %w", err)
+	}
+
+	if flag.NArg() > 0 {
+		switch flag.Arg(0) {
+		case "discover":
+			return doDiscoverCommand(flag.Args()[1:])
+		case "doctor":
+			return doDoctorCommand(configFilePath, flag.Args()[1:])
+		case "pair":
+			return doPairCommand(configFilePath, flag.Args()[1:])
+		case "schedule":
+			return doScheduleCommand(*device, flag.Args()[1:])
+		}
 	}
-	dir := usr.HomeDir
-	configFilePath := filepath.Join(dir, defaultConfigFile)
 
 	cfg, err := ini.Load(configFilePath)
 	if err != nil {
-		fmt.Println("error: failed to read file:", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to read file: %w", err)
 	}
 
-	client := Client{
-		Host:    cfg.Section("").Key("host").String(),
-		Token:   cfg.Section("").Key("access_token").String(),
-		Verbose: *verbose,
+	shutdownTelemetry, err := setupTelemetry(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to set up telemetry: %w", err)
+	}
+	defer shutdownTelemetry()
+
+	section, err := deviceSection(cfg, *device)
+	if err != nil {
+		return fmt.Errorf("unknown device: %v", *device)
+	}
+
+	client := newClient(section)
+	if client.StreamVersion != "" && client.StreamVersion != StreamV1 && client.StreamVersion != StreamV2 {
+		return errors.New("stream version must be v1 or v2")
 	}
 
 	if *verbose {
 		fmt.Printf("Host: %s\n\n", client.Host)
 	}
 
-	if flag.NArg() > 0 {
-		cmd := flag.Arg(0)
+	if *queueChanges {
+		client.Queue = &RequestQueue{Device: *device}
+		err := flushQueue(client)
+		if err != nil {
+			return err
+		}
+	}
+
+	return runCommand(client, cfg, section, configFilePath, flag.Args())
+}
+
+// runCommand runs a picoleaf command, given as its arguments.
+func runCommand(client Client, cfg *ini.File, section *ini.Section, configFilePath string, args []string) error {
+	args = expandAlias(cfg.Section("aliases"), args)
+	client.History = &HistoryRecorder{Device: *device}
+	if len(args) > 0 {
+		cmd := args[0]
 		switch cmd {
+		case "anim":
+			return doAnimCommand(client, cfg.Section("colors"), args[1:])
+		case "autotemp":
+			return doAutotempCommand(client, cfg.Section("autotemp"), args[1:])
+		case "backup":
+			return doBackupCommand(client, args[1:])
 		case "brightness":
-			doBrightnessCommand(client, flag.Args()[1:])
+			return doBrightnessCommand(client, args[1:])
+		case "calibrate":
+			return doCalibrateCommand(client, cfg, section, configFilePath, args[1:])
+		case "circadian":
+			return doCircadianCommand(client, cfg.Section("circadian"), args[1:])
+		case "clock":
+			return doClockCommand(client, cfg.Section("colors"), args[1:])
+		case "color":
+			return doColorCommand(client, cfg.Section("colors"), args[1:])
+		case "describe":
+			return doDescribeCommand(client, args[1:])
+		case "discover":
+			return doDiscoverCommand(args[1:])
 		case "effect":
-			doEffectCommand(client, flag.Args()[1:])
+			return doEffectCommand(client, cfg, section, args[1:])
+		case "events":
+			return doEventsCommand(client, args[1:])
+		case "fav":
+			return doFavoriteCommand(client, cfg, args[1:])
+		case "fx":
+			return doFxCommand(client, cfg.Section("colors"), args[1:])
+		case "gif":
+			return doGIFCommand(client, args[1:])
 		case "hsl":
-			doHSLCommand(client, flag.Args()[1:])
+			return doHSLCommand(client, args[1:])
+		case "hue":
+			return doHueCommand(client, args[1:])
+		case "identify":
+			err := client.Identify()
+			if err != nil {
+				return fmt.Errorf("failed to identify Nanoleaf: %w", err)
+			}
+		case "image":
+			return doImageCommand(client, args[1:])
+		case "info":
+			return doInfoCommand(client, args[1:])
+		case "keys":
+			return doKeysCommand(client, cfg, args[1:])
+		case "layout":
+			return doLayoutCommand(client, args[1:])
+		case "music":
+			return doMusicCommand(client, args[1:])
+		case "notify":
+			return doNotifyCommand(client, cfg.Section("colors"), args[1:])
 		case "off":
-			err = client.Off()
+			if _, err := parseStateFlags(&client, "off", args[1:]); err != nil {
+				return err
+			}
+			err := client.Off()
 			if err != nil {
-				fmt.Println("error: failed to turn off Nanoleaf:", err)
-				os.Exit(1)
+				return fmt.Errorf("failed to turn off Nanoleaf: %w", err)
 			}
 		case "on":
-			err = client.On()
+			if _, err := parseStateFlags(&client, "on", args[1:]); err != nil {
+				return err
+			}
+			err := client.On()
 			if err != nil {
-				fmt.Println("error: failed to turn on Nanoleaf:", err)
-				os.Exit(1)
+				return fmt.Errorf("failed to turn on Nanoleaf: %w", err)
 			}
+		case "pair":
+			return doPairCommand(configFilePath, args[1:])
 		case "panel":
-			doPanelCommand(client, flag.Args()[1:])
+			return doPanelCommand(client, cfg.Section("colors"), args[1:])
+		case "pick":
+			return doPickCommand(client, args[1:])
+		case "play":
+			return doPlayCommand(client, cfg.Section("colors"), args[1:])
+		case "playlist":
+			return doPlaylistCommand(client, cfg, args[1:])
+		case "preset":
+			return doPresetCommand(client, cfg, args[1:])
+		case "progress":
+			return doProgressCommand(client, cfg.Section("colors"), args[1:])
+		case "queue":
+			return doQueueCommand(client, *device, args[1:])
+		case "raw":
+			return doRawCommand(client, args[1:])
+		case "rhythm":
+			return doRhythmCommand(client, cfg, args[1:])
+		case "restore":
+			return doRestoreCommand(client, *device, args[1:])
 		case "rgb":
-			doRGBCommand(client, flag.Args()[1:])
-		case "temp":
-			doColorTemperatureCommand(client, flag.Args()[1:])
+			return doRGBCommand(client, args[1:])
+		case "rules":
+			return doRulesCommand(client, cfg, args[1:])
+		case "run":
+			return doRunCommand(client, cfg, section, configFilePath, args[1:])
+		case "sat":
+			return doSaturationCommand(client, args[1:])
+		case "scene":
+			return doSceneCommand(cfg, *device, args[1:])
+		case "schedule":
+			return doScheduleCommand(*device, args[1:])
+		case "serve":
+			return doServeCommand(cfg, args[1:])
+		case "sleep":
+			return doSleepCommand(client, args[1:])
+		case "status":
+			return doStatusCommand(client, args[1:])
+		case "text":
+			return doTextCommand(client, cfg.Section("colors"), args[1:])
+		case "touch-daemon":
+			return doTouchDaemonCommand(client, cfg, args[1:])
+		case "tui":
+			return doTUICommand(client, args[1:])
+		case "undo":
+			return doUndoCommand(client, *device, args[1:])
+		case "video":
+			return doVideoCommand(client, args[1:])
+		case "wake":
+			return doWakeCommand(client, args[1:])
+		case "watch":
+			return doWatchCommand(client, args[1:])
+		case "weather":
+			return doWeatherCommand(client, args[1:])
+		case "webhook":
+			return doWebhookCommand(client, cfg, args[1:])
+		case "ct", "temp":
+			return doColorTemperatureCommand(client, args[1:])
 		default:
-			usage()
+			return usage()
 		}
 	} else {
-		usage()
+		return usage()
 	}
+	return nil
 }
 
-func doBrightnessCommand(client Client, args []string) {
-	if len(args) < 1 {
-		fmt.Println("usage: picoleaf brightness <brightness>")
-		os.Exit(1)
+// defaultAliases are command aliases available without configuration.
+var defaultAliases = map[string]string{
+	"red":   "color red",
+	"white": "color white",
+}
+
+// expandAlias replaces an aliased command name at the start of args with
+// its expansion. Aliases in the config section take precedence over the
+// defaults. Aliases are not expanded recursively.
+func expandAlias(aliases *ini.Section, args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+
+	expansion, ok := defaultAliases[args[0]]
+	if aliases.HasKey(args[0]) {
+		expansion, ok = aliases.Key(args[0]).String(), true
+	}
+	if !ok {
+		return args
+	}
+	return append(strings.Fields(expansion), args[1:]...)
+}
+
+// newStateFlagSet returns a flag set with the flags shared by
+// state-changing commands, which are parsed into the client.
+func newStateFlagSet(client *Client, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.DurationVar(&client.Duration, "duration", 0, "Transition duration, e.g. 5s")
+	fs.DurationVar(&client.Duration, "fade", 0, "Alias for --duration")
+	return fs
+}
+
+// parseFlags parses a command's flags, which are defined on a flag set made
+// with flag.ContinueOnError. Errors have already been reported by the flag
+// set, so they're returned as a flagError.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err != nil {
+		return &flagError{err}
+	}
+	return nil
+}
+
+// parseArgs parses flags that may appear before, between or after
+// positional arguments, and returns the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		err := parseFlags(fs, args)
+		if err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// brightnessFlag adds a --brightness flag to a color command's flag set.
+func brightnessFlag(fs *flag.FlagSet) *int {
+	return fs.Int("brightness", 0, "Brightness to set along with the color, 0-100")
+}
+
+// isFlagSet reports whether a flag was given on the command line.
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// checkBrightnessFlag returns a given --brightness flag, or an error if it's
+// out of range.
+func checkBrightnessFlag(brightness int) (int, error) {
+	if brightness < 0 || brightness > 100 {
+		return 0, errors.New("brightness must be an integer 0-100")
+	}
+	return brightness, nil
+}
+
+// parseStateFlags parses the flags shared by state-changing commands into
+// the client and returns the remaining arguments.
+func parseStateFlags(client *Client, name string, args []string) ([]string, error) {
+	fs := newStateFlagSet(client, name)
+	return parseArgs(fs, args)
+}
+
+// getPanelInfo fetches the panel info, describing any failure.
+func getPanelInfo(client Client) (*PanelInfo, error) {
+	panelInfo, err := client.GetPanelInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Nanoleaf state: %w", err)
+	}
+	return panelInfo, nil
+}
+
+// printFormatted prints data using the -format template, if one was given,
+// and reports whether it did.
+func printFormatted(data interface{}) (bool, error) {
+	if *format == "" {
+		return false, nil
+	}
+
+	tmpl, err := template.New("format").Parse(*format)
+	if err != nil {
+		return false, fmt.Errorf("invalid format: %w", err)
+	}
+
+	err = tmpl.Execute(os.Stdout, data)
+	fmt.Println()
+	if err != nil {
+		return false, fmt.Errorf("failed to format output: %w", err)
+	}
+	return true, nil
+}
+
+// printProperty prints a state property's value followed by its range.
+func printProperty(value int, min *int, max *int) {
+	if min != nil && max != nil {
+		fmt.Printf("%d [%d-%d]\n", value, *min, *max)
+	} else {
+		fmt.Println(value)
+	}
+}
+
+// newClient returns a client for the device configured in section, with the
+// global flags applied.
+func newClient(section *ini.Section) Client {
+	client := Client{
+		Host:          section.Key("host").String(),
+		Token:         section.Key("access_token").String(),
+		StreamVersion: section.Key("stream_version").String(),
+		Verbose:       *verbose,
+	}
+	if section.Key("color_model").String() == "hsl" {
+		client.LegacyHSL = true
+	}
+	client.Calibration = loadCalibration(section)
+	client.Safety = loadSafetyLimits(section)
+	client.Cache = NewResponseCache()
+	if *tracePath != "" {
+		client.Trace = NewTrace(*tracePath)
+	}
+	if *recordPath != "" {
+		client.Transcript = NewTranscript(*recordPath)
+	}
+	if *streamVersion != "" {
+		client.StreamVersion = *streamVersion
+	}
+	return client
+}
+
+// loadCalibration reads a device's color calibration from its config
+// section, returning nil if none is configured.
+func loadCalibration(section *ini.Section) *Calibration {
+	keys := []string{"gamma", "red_gain", "green_gain", "blue_gain"}
+	configured := false
+	for _, key := range keys {
+		if section.HasKey(key) {
+			configured = true
+		}
+	}
+	if !configured {
+		return nil
+	}
+
+	return &Calibration{
+		Gamma:     section.Key("gamma").MustFloat64(DefaultCalibration.Gamma),
+		RedGain:   section.Key("red_gain").MustFloat64(DefaultCalibration.RedGain),
+		GreenGain: section.Key("green_gain").MustFloat64(DefaultCalibration.GreenGain),
+		BlueGain:  section.Key("blue_gain").MustFloat64(DefaultCalibration.BlueGain),
+	}
+}
+
+// loadSafetyLimits reads a device's safety limits from its config section.
+// Limits looser than the defaults are ignored.
+func loadSafetyLimits(section *ini.Section) *SafetyLimits {
+	limits := SafetyLimits{
+		MaxFlashRate:        section.Key("max_flash_rate").MustFloat64(DefaultSafetyLimits.MaxFlashRate),
+		MaxBrightnessChange: section.Key("max_brightness_change").MustFloat64(DefaultSafetyLimits.MaxBrightnessChange),
+	}.Tighten()
+	return &limits
+}
+
+// deviceSection returns the config section for the named device. Without a
+// name, the top-level settings are used, falling back to the first paired
+// device.
+func deviceSection(cfg *ini.File, name string) (*ini.Section, error) {
+	if name != "" {
+		return cfg.GetSection(deviceSectionPrefix + name)
+	}
+
+	root := cfg.Section("")
+	if root.HasKey("host") {
+		return root, nil
+	}
+	for _, section := range cfg.Sections() {
+		if strings.HasPrefix(section.Name(), deviceSectionPrefix) {
+			return section, nil
+		}
+	}
+	return root, nil
+}
+
+func doBrightnessCommand(client Client, args []string) error {
+	args, err := parseStateFlags(&client, "brightness", args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		panelInfo, err := getPanelInfo(client)
+		if err != nil {
+			return err
+		}
+		brightness := panelInfo.State.Brightness
+		if ok, err := printFormatted(brightness); ok || err != nil {
+			return err
+		}
+		printProperty(brightness.Value, brightness.Min, brightness.Max)
+		return nil
 	}
 
 	brightness, err := strconv.Atoi(args[0])
 	if err != nil || brightness < 0 || brightness > 100 {
-		fmt.Println("error: temperature must be an integer 0-100")
-		os.Exit(1)
+		return errors.New("brightness must be an integer 0-100")
 	}
 
 	err = client.SetBrightness(brightness)
 	if err != nil {
-		fmt.Println("error: failed to set brightness:", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to set brightness: %w", err)
 	}
+	return nil
 }
 
-func doColorTemperatureCommand(client Client, args []string) {
-	if len(args) < 1 {
-		fmt.Println("usage: picoleaf temp <temperature>")
-		os.Exit(1)
+func doColorCommand(client Client, colors *ini.Section, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf color [--duration <duration>] [--brightness <brightness>] <name|#hex>\n       picoleaf color [--duration <duration>] [--brightness <brightness>] --xy <x>,<y>[,<Y>]")
+	}
+
+	fs := newStateFlagSet(&client, "color")
+	xy := fs.String("xy", "", "CIE 1931 chromaticity as x,y or x,y,Y")
+	brightness := brightnessFlag(fs)
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	var c RGB
+	if *xy != "" {
+		if len(args) != 0 {
+			return usage()
+		}
+		c, err = parseXYColor(*xy)
+	} else {
+		if len(args) != 1 {
+			return usage()
+		}
+		c, err = parseColor(args[0], colors)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Set brightness along with the color, so they fade together.
+	state := client.rgbState(int(c.Red), int(c.Green), int(c.Blue))
+	if isFlagSet(fs, "brightness") {
+		state.Brightness.Value, err = checkBrightnessFlag(*brightness)
+		if err != nil {
+			return err
+		}
+	}
+	err = client.SetState(state)
+	if err != nil {
+		return fmt.Errorf("failed to set color: %w", err)
+	}
+	return nil
+}
+
+func doColorTemperatureCommand(client Client, args []string) error {
+	fs := newStateFlagSet(&client, "temp")
+	brightness := brightnessFlag(fs)
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		panelInfo, err := getPanelInfo(client)
+		if err != nil {
+			return err
+		}
+		ct := panelInfo.State.ColorTemperature
+		if ok, err := printFormatted(ct); ok || err != nil {
+			return err
+		}
+		printProperty(ct.Value, ct.Min, ct.Max)
+		return nil
+	}
+
+	temp, ok := namedTemperatures[strings.ToLower(args[0])]
+	if !ok {
+		var err error
+		temp, err = strconv.Atoi(args[0])
+		if err != nil || temp <= 0 {
+			return errors.New("temperature must be warm, neutral, cool, daylight, or a positive integer")
+		}
+	}
+
+	panelInfo, err := client.GetPanelInfo()
+	if err != nil {
+		return fmt.Errorf("failed to get Nanoleaf state: %w", err)
+	}
+
+	ct := panelInfo.State.ColorTemperature
+	if ct.Min != nil && temp < *ct.Min {
+		temp = *ct.Min
+	}
+	if ct.Max != nil && temp > *ct.Max {
+		temp = *ct.Max
 	}
 
-	temp, err := strconv.Atoi(args[0])
-	if err != nil || temp < 1200 || temp > 6500 {
-		fmt.Println("error: temperature must be an integer 1200-6500")
-		os.Exit(1)
+	state := State{ColorTemperature: &ColorTemperatureProperty{Value: temp}}
+	if isFlagSet(fs, "brightness") {
+		value, err := checkBrightnessFlag(*brightness)
+		if err != nil {
+			return err
+		}
+		state.Brightness = &BrightnessProperty{Value: value}
+	}
+	err = client.SetState(state)
+	if err != nil {
+		return fmt.Errorf("failed to set color temperature: %w", err)
+	}
+	return nil
+}
+
+func doDiscoverCommand(args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf discover [--scan <subnet>] [--timeout <duration>]")
+	}
+
+	fs := flag.NewFlagSet("discover", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	subnet := fs.String("scan", "", "Probe every address in an IPv4 subnet, e.g. 192.168.1.0/24")
+	timeout := fs.Duration("timeout", 0, "How long to wait for responses (per host when scanning)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return usage()
 	}
 
-	err = client.SetColorTemperature(temp)
+	var devices []Device
+	var err error
+	if *subnet != "" {
+		if *timeout == 0 {
+			*timeout = scanTimeout
+		}
+		devices, err = Scan(*subnet, *timeout)
+	} else {
+		if *timeout == 0 {
+			*timeout = discoveryTimeout
+		}
+		devices, err = Discover(*timeout)
+	}
 	if err != nil {
-		fmt.Println("error: failed to set color temperature:", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to discover devices: %w", err)
+	}
+
+	for _, d := range devices {
+		fmt.Printf("%-24s %-21s %s\n", d.Name, d.Host, d.ID)
 	}
+
+	_, err = UpdateDeviceCache(devices)
+	if err != nil {
+		return fmt.Errorf("failed to update device cache: %w", err)
+	}
+	return nil
 }
 
-func doEffectCommand(client Client, args []string) {
-	usage := func() {
-		fmt.Println("usage: picoleaf effect list")
-		fmt.Println("       picoleaf effect select <name>")
-		fmt.Println("       picoleaf effect custom [<panel> <red> <green> <blue> <transition time>] ...")
-		os.Exit(1)
+func doPairCommand(configFilePath string, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf pair <host>\n       picoleaf pair --all")
+	}
+
+	fs := flag.NewFlagSet("pair", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	all := fs.Bool("all", false, "Pair every Nanoleaf in pairing mode on the network")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if (*all && fs.NArg() != 0) || (!*all && fs.NArg() != 1) {
+		return usage()
+	}
+
+	cfg, err := ini.LooseLoad(configFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if *all {
+		devices, err := Discover(discoveryTimeout)
+		if err != nil {
+			return fmt.Errorf("failed to discover devices: %w", err)
+		}
+
+		paired := 0
+		for _, d := range devices {
+			name := d.Name
+			if name == "" {
+				name = d.Host
+			}
+
+			token, err := Pair(d.Host)
+			if err != nil {
+				fmt.Printf("%s (%s): skipped: %s\n", name, d.Host, err)
+				continue
+			}
+
+			section := cfg.Section(deviceSectionPrefix + name)
+			section.Key("host").SetValue(d.Host)
+			section.Key("access_token").SetValue(token)
+			fmt.Printf("%s (%s): paired\n", name, d.Host)
+			paired++
+		}
+
+		if paired == 0 {
+			return errors.New("no Nanoleaf devices in pairing mode found")
+		}
+	} else {
+		host := fs.Arg(0)
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(host, strconv.Itoa(DefaultAPIPort))
+		}
+
+		token, err := Pair(host)
+		if err != nil {
+			return fmt.Errorf("failed to pair: %w", err)
+		}
+
+		section := cfg.Section("")
+		section.Key("host").SetValue(host)
+		section.Key("access_token").SetValue(token)
+	}
+
+	err = cfg.SaveTo(configFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+func doEffectCommand(client Client, cfg *ini.File, section *ini.Section, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf effect list\n       picoleaf effect select <name>\n       picoleaf effect next\n       picoleaf effect prev\n       picoleaf effect random [--exclude <name>,...]\n       picoleaf effect rotate [--every <duration>] [--list <name>,...]\n       picoleaf effect custom [<panel> <red> <green> <blue> <transition time>] ...\n       picoleaf effect show <name>\n       picoleaf effect preview <name|file> [--for <duration>]\n       picoleaf effect create\n       picoleaf effect from-palette <color>,... [--plugin <plugin>] [--speed <seconds>]\n       picoleaf effect compile <file> [--name <name>]\n       picoleaf effect export <name> [-o <file>]\n       picoleaf effect import <file> [--name <name>]\n       picoleaf effect rename <old> <new>\n       picoleaf effect delete [--force] <name> ...\n       picoleaf effect gc [--dry-run]")
 	}
 
 	if len(args) < 1 {
-		usage()
+		return usage()
 	}
 
 	command := args[0]
@@ -162,32 +847,27 @@ func doEffectCommand(client Client, args []string) {
 			offset := numFrameArgs * i
 			panelID, err := strconv.ParseUint(customArgs[offset], 10, 16)
 			if err != nil {
-				fmt.Printf("error: expected panel ID between 0-%d, got %s", math.MaxUint16, customArgs[offset])
-				os.Exit(1)
+				return fmt.Errorf("expected panel ID between 0-%d, got %s", math.MaxUint16, customArgs[offset])
 			}
 
 			red, err := strconv.ParseUint(customArgs[offset+1], 10, 8)
 			if err != nil {
-				fmt.Printf("error: expected red value between 0-%d, got %s", math.MaxUint8, customArgs[offset+1])
-				os.Exit(1)
+				return fmt.Errorf("expected red value between 0-%d, got %s", math.MaxUint8, customArgs[offset+1])
 			}
 
 			green, err := strconv.ParseUint(customArgs[offset+2], 10, 8)
 			if err != nil {
-				fmt.Printf("error: expected green value between 0-%d, got %s", math.MaxUint8, customArgs[offset+2])
-				os.Exit(1)
+				return fmt.Errorf("expected green value between 0-%d, got %s", math.MaxUint8, customArgs[offset+2])
 			}
 
 			blue, err := strconv.ParseUint(customArgs[offset+3], 10, 8)
 			if err != nil {
-				fmt.Printf("error: expected blue value between 0-%d, got %s", math.MaxUint8, customArgs[offset+3])
-				os.Exit(1)
+				return fmt.Errorf("expected blue value between 0-%d, got %s", math.MaxUint8, customArgs[offset+3])
 			}
 
 			transitionTime, err := strconv.ParseUint(customArgs[offset+4], 10, 16)
 			if err != nil {
-				fmt.Printf("error: expected transition time between 0-%d, got %s", math.MaxUint16, customArgs[offset+4])
-				os.Exit(1)
+				return fmt.Errorf("expected transition time between 0-%d, got %s", math.MaxUint16, customArgs[offset+4])
 			}
 
 			frames[i].PanelID = uint16(panelID)
@@ -199,105 +879,126 @@ func doEffectCommand(client Client, args []string) {
 
 		err := client.SetCustomColors(frames)
 		if err != nil {
-			fmt.Println("error: failed to start external control:", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to start external control: %w", err)
 		}
+	case "delete":
+		return doEffectDeleteCommand(client, args[1:])
+	case "rename":
+		return doEffectRenameCommand(client, args[1:])
+	case "next":
+		return doEffectCycleCommand(client, "next", 1, args[1:])
+	case "prev":
+		return doEffectCycleCommand(client, "prev", -1, args[1:])
+	case "random":
+		return doEffectRandomCommand(client, section, args[1:])
+	case "rotate":
+		return doEffectRotateCommand(client, args[1:])
+	case "preview":
+		return doEffectPreviewCommand(client, args[1:])
+	case "create":
+		return doEffectCreateCommand(client, cfg.Section("colors"), args[1:])
+	case "from-palette":
+		return doEffectFromPaletteCommand(client, cfg.Section("colors"), args[1:])
+	case "compile":
+		return doEffectCompileCommand(client, cfg.Section("colors"), args[1:])
+	case "export":
+		return doEffectExportCommand(client, args[1:])
+	case "import":
+		return doEffectImportCommand(client, args[1:])
+	case "gc":
+		return doEffectGCCommand(client, args[1:])
+	case "show":
+		return doEffectShowCommand(client, args[1:])
 	case "list":
 		list, err := client.ListEffects()
 		if err != nil {
-			fmt.Println("error: failed retrieve effects list:", err)
-			os.Exit(1)
+			return fmt.Errorf("failed retrieve effects list: %w", err)
+		}
+		if ok, err := printFormatted(list); ok || err != nil {
+			return err
 		}
 		for _, name := range list {
 			fmt.Println(name)
 		}
 	case "select":
 		if len(args) != 2 {
-			fmt.Println("usage: picoleaf effect select <name>")
-			os.Exit(1)
+			return usageError("usage: picoleaf effect select <name>")
 		}
 
 		name := args[1]
 		err := client.SelectEffect(name)
 		if err != nil {
-			fmt.Println("error: failed to select effect:", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to select effect: %w", err)
 		}
 	default:
-		usage()
+		return usage()
 	}
+	return nil
 }
 
-func doPanelCommand(client Client, args []string) {
-	usage := func() {
-		fmt.Println("usage: picoleaf panel info")
-		fmt.Println("       picoleaf panel model")
-		fmt.Println("       picoleaf panel name")
-		fmt.Println("       picoleaf panel version")
-		os.Exit(1)
+func doInfoCommand(client Client, args []string) error {
+	fs := flag.NewFlagSet("info", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "Print the raw panel info JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return usageError("usage: picoleaf info [--json]")
+	}
+
+	if *asJSON {
+		body, err := client.Get("")
+		if err != nil {
+			return fmt.Errorf("failed to get Nanoleaf state: %w", err)
+		}
+
+		var out bytes.Buffer
+		err = json.Indent(&out, []byte(body), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to parse Nanoleaf state: %w", err)
+		}
+		fmt.Println(out.String())
+		return nil
+	}
+
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	if ok, err := printFormatted(panelInfo); ok || err != nil {
+		return err
+	}
+	printPanelInfo(panelInfo)
+	return nil
+}
+
+func doPanelCommand(client Client, colors *ini.Section, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf panel info\n       picoleaf panel model\n       picoleaf panel name\n       picoleaf panel set <panel>[,<panel>...] <color> ...\n       picoleaf panel set --region <region> <color>\n       picoleaf panel version")
+	}
+
+	if len(args) > 0 && args[0] == "set" {
+		return doPanelSetCommand(client, colors, args[1:])
 	}
 
 	if len(args) != 1 {
-		usage()
+		return usage()
 	}
 
 	panelInfo, err := client.GetPanelInfo()
 	if err != nil {
-		fmt.Println("error: failed to get Nanoleaf state:", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to get Nanoleaf state: %w", err)
+	}
+
+	if ok, err := printFormatted(panelInfo); ok || err != nil {
+		return err
 	}
 
 	command := args[0]
 	switch command {
 	case "info":
-		fmt.Println("Name:", panelInfo.Name)
-		fmt.Println()
-		fmt.Println("Manufacturer:", panelInfo.Manufacturer)
-		fmt.Println("Model:       ", panelInfo.Model)
-		fmt.Println("Serial No:   ", panelInfo.SerialNo)
-		fmt.Println()
-		fmt.Println("Firmware Version:", panelInfo.FirmwareVersion)
-		fmt.Println()
-		fmt.Println("State:")
-		fmt.Println("  On:  ", panelInfo.State.On.Value)
-		fmt.Println("  Mode:", panelInfo.State.ColorMode)
-		fmt.Println()
-		fmt.Printf("  Hue:        %3d° [%d°-%d°]\n", panelInfo.State.Hue.Value, *panelInfo.State.Hue.Min, *panelInfo.State.Hue.Max)
-		fmt.Printf("  Saturation: %3d  [%d-%d]\n", panelInfo.State.Saturation.Value, *panelInfo.State.Saturation.Min, *panelInfo.State.Saturation.Max)
-		fmt.Printf("  Brightness: %3d  [%d-%d]\n", panelInfo.State.Brightness.Value, *panelInfo.State.Brightness.Min, *panelInfo.State.Brightness.Max)
-		fmt.Println()
-		fmt.Printf("  Color Temperature: %4dK [%dK-%dK]\n", panelInfo.State.ColorTemperature.Value, *panelInfo.State.ColorTemperature.Min, *panelInfo.State.ColorTemperature.Max)
-		fmt.Println()
-		fmt.Println("Effects:")
-		fmt.Println("  Selected:", panelInfo.Effects.Selected)
-		fmt.Println("  Available:")
-		for _, effect := range panelInfo.Effects.List {
-			fmt.Println("  -", effect)
-		}
-		fmt.Println()
-		fmt.Println("Layout:")
-		fmt.Printf("  Orientation: %d° [%d°-%d°]\n", panelInfo.PanelLayout.GlobalOrientation.Value, panelInfo.PanelLayout.GlobalOrientation.Min, panelInfo.PanelLayout.GlobalOrientation.Max)
-		fmt.Println("  Panels:     ", panelInfo.PanelLayout.Layout.NumPanels)
-		fmt.Println("  Side Length:", panelInfo.PanelLayout.Layout.SideLength)
-		fmt.Println()
-		fmt.Println("  Panel Positions:")
-		for _, panel := range panelInfo.PanelLayout.Layout.PositionData {
-			fmt.Printf("  - %3d: (%d, %d, %d°)\n", panel.PanelID, panel.X, panel.Y, panel.O)
-		}
-		fmt.Println()
-		fmt.Println("Rhythm:")
-		fmt.Println("  ID:      ", panelInfo.Rhythm.ID)
-		fmt.Printf("  Position: (%.0f, %.0f, %.0f°)\n", panelInfo.Rhythm.Position.X, panelInfo.Rhythm.Position.Y, panelInfo.Rhythm.Position.O)
-		fmt.Println()
-		fmt.Println("  Connected:    ", panelInfo.Rhythm.Connected)
-		fmt.Println("  Aux Available:", panelInfo.Rhythm.AuxAvailable)
-		fmt.Println("  Active:       ", panelInfo.Rhythm.Active)
-		fmt.Println("  Mode:         ", panelInfo.Rhythm.Mode)
-		fmt.Println()
-		fmt.Println("  Versions:")
-		fmt.Println("    Hardware:", panelInfo.Rhythm.HardwareVersion)
-		fmt.Println("    Firmware:", panelInfo.Rhythm.FirmwareVersion)
-		fmt.Println()
+		printPanelInfo(panelInfo)
 	case "layout":
 		fmt.Printf("Orientation: %d° [%d°-%d°]\n", panelInfo.PanelLayout.GlobalOrientation.Value, panelInfo.PanelLayout.GlobalOrientation.Min, panelInfo.PanelLayout.GlobalOrientation.Max)
 		fmt.Println("Panels:     ", panelInfo.PanelLayout.Layout.NumPanels)
@@ -330,68 +1031,272 @@ func doPanelCommand(client Client, args []string) {
 		fmt.Println("  Firmware:", panelInfo.Rhythm.FirmwareVersion)
 		fmt.Println()
 	default:
-		usage()
+		return usage()
+	}
+	return nil
+}
+
+func doHueCommand(client Client, args []string) error {
+	args, err := parseStateFlags(&client, "hue", args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		panelInfo, err := getPanelInfo(client)
+		if err != nil {
+			return err
+		}
+		hue := panelInfo.State.Hue
+		if ok, err := printFormatted(hue); ok || err != nil {
+			return err
+		}
+		printProperty(hue.Value, hue.Min, hue.Max)
+		return nil
+	}
+
+	hue, err := strconv.Atoi(args[0])
+	if err != nil || hue < 0 || hue > 360 {
+		return errors.New("hue must be an integer 0-360")
+	}
+
+	err = client.SetHue(hue)
+	if err != nil {
+		return fmt.Errorf("failed to set hue: %w", err)
 	}
+	return nil
 }
 
-func doHSLCommand(client Client, args []string) {
+func doSaturationCommand(client Client, args []string) error {
+	args, err := parseStateFlags(&client, "sat", args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		panelInfo, err := getPanelInfo(client)
+		if err != nil {
+			return err
+		}
+		sat := panelInfo.State.Saturation
+		if ok, err := printFormatted(sat); ok || err != nil {
+			return err
+		}
+		printProperty(sat.Value, sat.Min, sat.Max)
+		return nil
+	}
+
+	sat, err := strconv.Atoi(args[0])
+	if err != nil || sat < 0 || sat > 100 {
+		return errors.New("saturation must be an integer 0-100")
+	}
+
+	err = client.SetSaturation(sat)
+	if err != nil {
+		return fmt.Errorf("failed to set saturation: %w", err)
+	}
+	return nil
+}
+
+func doHSLCommand(client Client, args []string) error {
+	args, err := parseStateFlags(&client, "hsl", args)
+	if err != nil {
+		return err
+	}
 	if len(args) != 3 {
-		fmt.Println("usage: picoleaf hsl <hue> <saturation> <lightness>")
-		os.Exit(1)
+		return usageError("usage: picoleaf hsl [--duration <duration>] <hue> <saturation> <lightness>")
 	}
 
 	hue, err := strconv.Atoi(args[0])
 	if err != nil || hue < 0 || hue > 360 {
-		fmt.Println("error: hue must be an integer 0-100")
-		os.Exit(1)
+		return errors.New("hue must be an integer 0-100")
 	}
 
 	sat, err := strconv.Atoi(args[1])
 	if err != nil || sat < 0 || sat > 100 {
-		fmt.Println("error: saturation must be an integer 0-360")
-		os.Exit(1)
+		return errors.New("saturation must be an integer 0-360")
 	}
 
 	lightness, err := strconv.Atoi(args[2])
 	if err != nil || lightness < 0 || lightness > 100 {
-		fmt.Println("error: lightness must be an integer 0-100")
-		os.Exit(1)
+		return errors.New("lightness must be an integer 0-100")
 	}
 
 	err = client.SetHSL(hue, sat, lightness)
 	if err != nil {
-		fmt.Println("error: failed to set HSL:", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to set HSL: %w", err)
 	}
+	return nil
 }
 
-func doRGBCommand(client Client, args []string) {
+func doRGBCommand(client Client, args []string) error {
+	args, err := parseStateFlags(&client, "rgb", args)
+	if err != nil {
+		return err
+	}
 	if len(args) != 3 {
-		fmt.Println("usage: picoleaf rgb <red> <green> <blue>")
-		os.Exit(1)
+		return usageError("usage: picoleaf rgb [--duration <duration>] <red> <green> <blue>")
 	}
 
 	red, err := strconv.Atoi(args[0])
 	if err != nil || red < 0 || red > 255 {
-		fmt.Println("error: red must be an integer 0-255")
-		os.Exit(1)
+		return errors.New("red must be an integer 0-255")
 	}
 
 	green, err := strconv.Atoi(args[1])
 	if err != nil || green < 0 || green > 255 {
-		fmt.Println("error: green must be an integer 0-255")
-		os.Exit(1)
+		return errors.New("green must be an integer 0-255")
 	}
 
 	blue, err := strconv.Atoi(args[2])
 	if err != nil || blue < 0 || blue > 255 {
-		fmt.Println("error: blue must be an integer 0-255")
-		os.Exit(1)
+		return errors.New("blue must be an integer 0-255")
 	}
 
 	err = client.SetRGB(red, green, blue)
 	if err != nil {
-		fmt.Println("error: failed to set RGB:", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to set RGB: %w", err)
+	}
+	return nil
+}
+
+func doStatusCommand(client Client, args []string) error {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	panels := fs.Bool("panels", false, "Include per-panel colors")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return usageError("usage: picoleaf status [--panels]")
+	}
+
+	panelInfo, err := client.GetPanelInfo()
+	if err != nil {
+		return fmt.Errorf("failed to get Nanoleaf state: %w", err)
+	}
+
+	if ok, err := printFormatted(panelInfo); ok || err != nil {
+		return err
+	}
+
+	power := "off"
+	if panelInfo.State.On.Value {
+		power = "on"
+	}
+
+	rhythm := "not connected"
+	if panelInfo.Rhythm.Connected {
+		rhythm = "connected"
+		if panelInfo.Rhythm.Active {
+			rhythm += ", active"
+		}
+		if panelInfo.Rhythm.Mode == RhythmAux {
+			rhythm += " (aux)"
+		} else {
+			rhythm += " (microphone)"
+		}
+	}
+
+	fmt.Println("Power:      ", power)
+	fmt.Printf("Brightness:  %d%%\n", panelInfo.State.Brightness.Value)
+	fmt.Println("Color Mode: ", panelInfo.State.ColorMode)
+	fmt.Println("Effect:     ", panelInfo.Effects.Selected)
+	fmt.Printf("Temperature: %dK\n", panelInfo.State.ColorTemperature.Value)
+	fmt.Println("Rhythm:     ", rhythm)
+
+	if *panels {
+		fmt.Println()
+		printPanelColors(client, panelInfo)
 	}
+	return nil
+}
+
+// printPanelColors prints each panel's current color as a swatch.
+func printPanelColors(client Client, panelInfo *PanelInfo) {
+	colors := currentPanelColors(client, panelInfo)
+
+	fmt.Println("Panels:")
+	for _, panel := range panelInfo.PanelLayout.Layout.PositionData {
+		c, ok := colors[uint16(panel.PanelID)]
+		if ok {
+			fmt.Printf("  %5d %s #%02x%02x%02x\n", panel.PanelID, ansiSwatch(c), c.Red, c.Green, c.Blue)
+		} else {
+			fmt.Printf("  %5d      unknown\n", panel.PanelID)
+		}
+	}
+}
+
+// currentPanelColors returns the color of each panel, keyed by panel ID.
+// Colors can only be recovered when a static or custom effect is selected;
+// otherwise the map is empty.
+func currentPanelColors(client Client, panelInfo *PanelInfo) map[uint16]RGB {
+	colors := make(map[uint16]RGB)
+	effect, err := client.RequestEffect(panelInfo.Effects.Selected)
+	if err != nil || effect.AnimData == "" {
+		return colors
+	}
+
+	animation, err := ParseAnimData(effect.AnimData)
+	if err != nil {
+		return colors
+	}
+
+	for _, panel := range animation {
+		if len(panel.Frames) > 0 {
+			frame := panel.Frames[len(panel.Frames)-1]
+			colors[panel.PanelID] = RGB{frame.Red, frame.Green, frame.Blue}
+		}
+	}
+	return colors
+}
+
+// printPanelInfo prints everything known about the panels.
+func printPanelInfo(panelInfo *PanelInfo) {
+	fmt.Println("Name:", panelInfo.Name)
+	fmt.Println()
+	fmt.Println("Manufacturer:", panelInfo.Manufacturer)
+	fmt.Println("Model:       ", panelInfo.Model)
+	fmt.Println("Serial No:   ", panelInfo.SerialNo)
+	fmt.Println()
+	fmt.Println("Firmware Version:", panelInfo.FirmwareVersion)
+	fmt.Println()
+	fmt.Println("State:")
+	fmt.Println("  On:  ", panelInfo.State.On.Value)
+	fmt.Println("  Mode:", panelInfo.State.ColorMode)
+	fmt.Println()
+	fmt.Printf("  Hue:        %3d° [%d°-%d°]\n", panelInfo.State.Hue.Value, *panelInfo.State.Hue.Min, *panelInfo.State.Hue.Max)
+	fmt.Printf("  Saturation: %3d  [%d-%d]\n", panelInfo.State.Saturation.Value, *panelInfo.State.Saturation.Min, *panelInfo.State.Saturation.Max)
+	fmt.Printf("  Brightness: %3d  [%d-%d]\n", panelInfo.State.Brightness.Value, *panelInfo.State.Brightness.Min, *panelInfo.State.Brightness.Max)
+	fmt.Println()
+	fmt.Printf("  Color Temperature: %4dK [%dK-%dK]\n", panelInfo.State.ColorTemperature.Value, *panelInfo.State.ColorTemperature.Min, *panelInfo.State.ColorTemperature.Max)
+	fmt.Println()
+	fmt.Println("Effects:")
+	fmt.Println("  Selected:", panelInfo.Effects.Selected)
+	fmt.Println("  Available:")
+	for _, effect := range panelInfo.Effects.List {
+		fmt.Println("  -", effect)
+	}
+	fmt.Println()
+	fmt.Println("Layout:")
+	fmt.Printf("  Orientation: %d° [%d°-%d°]\n", panelInfo.PanelLayout.GlobalOrientation.Value, panelInfo.PanelLayout.GlobalOrientation.Min, panelInfo.PanelLayout.GlobalOrientation.Max)
+	fmt.Println("  Panels:     ", panelInfo.PanelLayout.Layout.NumPanels)
+	fmt.Println("  Side Length:", panelInfo.PanelLayout.Layout.SideLength)
+	fmt.Println()
+	fmt.Println("  Panel Positions:")
+	for _, panel := range panelInfo.PanelLayout.Layout.PositionData {
+		fmt.Printf("  - %3d: (%d, %d, %d°)\n", panel.PanelID, panel.X, panel.Y, panel.O)
+	}
+	fmt.Println()
+	fmt.Println("Rhythm:")
+	fmt.Println("  ID:      ", panelInfo.Rhythm.ID)
+	fmt.Printf("  Position: (%.0f, %.0f, %.0f°)\n", panelInfo.Rhythm.Position.X, panelInfo.Rhythm.Position.Y, panelInfo.Rhythm.Position.O)
+	fmt.Println()
+	fmt.Println("  Connected:    ", panelInfo.Rhythm.Connected)
+	fmt.Println("  Aux Available:", panelInfo.Rhythm.AuxAvailable)
+	fmt.Println("  Active:       ", panelInfo.Rhythm.Active)
+	fmt.Println("  Mode:         ", panelInfo.Rhythm.Mode)
+	fmt.Println()
+	fmt.Println("  Versions:")
+	fmt.Println("    Hardware:", panelInfo.Rhythm.HardwareVersion)
+	fmt.Println("    Firmware:", panelInfo.Rhythm.FirmwareVersion)
+	fmt.Println()
 }
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Audio is read as signed 16-bit little-endian mono PCM at this rate.
+const musicSampleRate = 44100
+
+// musicFPS is how many times a second the visualization is updated.
+const musicFPS = 20
+
+// musicWindowSize is the number of samples analyzed for each update.
+const musicWindowSize = 2048
+
+// musicMinFrequency and musicMaxFrequency bound the spectrum spread
+// across the panels.
+const (
+	musicMinFrequency = 40.0
+	musicMaxFrequency = 10000.0
+)
+
+// musicPeakDecay is how much of the loudest recent level is kept each
+// update, so the visualization adjusts to the volume.
+const musicPeakDecay = 0.995
+
+// defaultRecorders are the commands used to capture audio from the default
+// input device, by operating system.
+var defaultRecorders = map[string]string{
+	"linux":  "arecord -q -f S16_LE -c 1 -r 44100 -t raw",
+	"darwin": "ffmpeg -loglevel error -f avfoundation -i :0 -ac 1 -ar 44100 -f s16le -",
+}
+
+func doMusicCommand(client Client, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf music [--input <command>|-]")
+	}
+
+	fs := flag.NewFlagSet("music", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	input := fs.String("input", defaultRecorders[runtime.GOOS], "Command that records 16-bit mono 44.1kHz PCM to stdout, or - to read it from stdin")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return usage()
+	}
+	if *input == "" {
+		return errors.New("no default recorder on this system, pass --input")
+	}
+
+	audio := io.Reader(os.Stdin)
+	if *input != "-" {
+		fields := strings.Fields(*input)
+		cmd := exec.Command(fields[0], fields[1:]...)
+		cmd.Stderr = os.Stderr
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return fmt.Errorf("failed to start recorder: %w", err)
+		}
+		err = cmd.Start()
+		if err != nil {
+			return fmt.Errorf("failed to start recorder: %w", err)
+		}
+		defer cmd.Process.Kill()
+		audio = stdout
+	}
+
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	panels := panelsLeftToRight(lightPanels(panelInfo))
+	if len(panels) == 0 {
+		return errors.New("no panels in layout")
+	}
+
+	hop := musicSampleRate / musicFPS
+	window := make([]float64, musicWindowSize)
+	chunk := make([]int16, hop)
+	peak := 0.0
+
+	return streamFrames(client, func() ([]SetPanelColor, time.Duration, bool, error) {
+		err := binary.Read(audio, binary.LittleEndian, chunk)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, 0, false, nil
+		}
+		if err != nil {
+			return nil, 0, false, err
+		}
+
+		copy(window, window[hop:])
+		for i, sample := range chunk {
+			window[len(window)-hop+i] = float64(sample) / math.MaxInt16
+		}
+
+		levels := bandLevels(magnitudeSpectrum(window), len(panels))
+		peak *= musicPeakDecay
+		for _, level := range levels {
+			peak = math.Max(peak, level)
+		}
+
+		frame := make([]SetPanelColor, len(panels))
+		for i, panel := range panels {
+			brightness := 0
+			if peak > 0 {
+				brightness = int(math.Round(100 * levels[i] / peak))
+			}
+			hue := 240 * i / len(panels)
+			r, g, b := hsvToRGB(hue, 100, brightness)
+			c := client.calibrated(RGB{uint8(r), uint8(g), uint8(b)})
+			frame[i] = SetPanelColor{PanelID: uint16(panel.PanelID), Red: c.Red, Green: c.Green, Blue: c.Blue, TransitionTime: 1}
+		}
+
+		// Reading audio paces the stream, so there's no delay.
+		return frame, 0, true, nil
+	})
+}
+
+// bandLevels splits a magnitude spectrum into n logarithmically spaced
+// frequency bands and returns the mean magnitude of each.
+func bandLevels(spectrum []float64, n int) []float64 {
+	binWidth := float64(musicSampleRate) / float64(2*len(spectrum))
+	ratio := math.Pow(musicMaxFrequency/musicMinFrequency, 1/float64(n))
+
+	levels := make([]float64, n)
+	low := musicMinFrequency
+	for i := range levels {
+		high := low * ratio
+		first := int(low / binWidth)
+		last := int(math.Ceil(high / binWidth))
+		if last > len(spectrum) {
+			last = len(spectrum)
+		}
+		if last <= first {
+			last = first + 1
+		}
+
+		sum := 0.0
+		for _, m := range spectrum[first:last] {
+			sum += m
+		}
+		levels[i] = sum / float64(last-first)
+		low = high
+	}
+	return levels
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// notifyPatterns are the ways a notification can flash, as the fraction
+// of each flash spent transitioning between colors.
+var notifyPatterns = map[string]float64{
+	"flash": 0,
+	"pulse": 1,
+}
+
+func doNotifyCommand(client Client, colors *ini.Section, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf notify [--color <color>] [--times <count>] [--period <duration>] [--pattern flash|pulse]")
+	}
+
+	fs := flag.NewFlagSet("notify", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	colorName := fs.String("color", "red", "Color to flash")
+	times := fs.Int("times", 3, "Number of flashes")
+	period := fs.Duration("period", time.Second, "Time per flash")
+	pattern := fs.String("pattern", "flash", "Flash pattern: flash or pulse")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 || *times < 1 || *period <= 0 {
+		return usage()
+	}
+	ramp, ok := notifyPatterns[*pattern]
+	if !ok {
+		return usage()
+	}
+	c, err := parseColor(*colorName, colors)
+	if err != nil {
+		return err
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	err = notify(client, c, *times, *period, ramp, signals)
+	if err != nil {
+		return fmt.Errorf("failed to notify: %w", err)
+	}
+	return nil
+}
+
+// notify flashes c on every panel times times, spending ramp of each half
+// period transitioning, then restores the previous state. It stops early if
+// stop receives.
+func notify(client Client, c RGB, times int, period time.Duration, ramp float64, stop <-chan os.Signal) (err error) {
+	// Flashing faster than the safety limits allow would only be smoothed
+	// into a blur, so slow down instead.
+	minPeriod := time.Duration(float64(time.Second) / client.safetyLimits().MaxFlashRate)
+	if period < minPeriod {
+		period = minPeriod
+	}
+
+	snapshot, err := client.Snapshot()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		restoreErr := client.ApplySnapshot(*snapshot)
+		if err == nil {
+			err = restoreErr
+		}
+	}()
+
+	panelInfo, err := client.GetPanelInfo()
+	if err != nil {
+		return err
+	}
+	panels := lightPanels(panelInfo)
+	stream, err := client.OpenStream()
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	half := period / 2
+	transition := uint16(math.Round(ramp * half.Seconds() * 10))
+	show := func(c RGB) error {
+		for _, panel := range panels {
+			stream.SetPanel(uint16(panel.PanelID), c.Red, c.Green, c.Blue, transition)
+		}
+		return stream.Flush()
+	}
+
+	for i := 0; i < times*2; i++ {
+		shown := RGB{}
+		if i%2 == 0 {
+			shown = c
+		}
+		err := show(shown)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-time.After(half):
+		case <-stop:
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// panelSetTransitionTime is the fade applied by `panel set`, in tenths of a
+// second.
+const panelSetTransitionTime = 5
+
+func doPanelSetCommand(client Client, colors *ini.Section, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf panel set <panel>[,<panel>...] <color> ...\n       picoleaf panel set --region <region> <color>")
+	}
+
+	fs := flag.NewFlagSet("set", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	region := fs.String("region", "", "Named region (top, left-half, ...) or ranges like x=0:300,y=200:")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+
+	if *region != "" {
+		if len(args) != 1 {
+			return usage()
+		}
+
+		c, err := parseColor(args[0], colors)
+		if err != nil {
+			return err
+		}
+
+		ids, err := regionPanelIDs(panelInfo, *region)
+		if err != nil {
+			return err
+		}
+
+		assignments := make(map[uint16]RGB)
+		for _, id := range ids {
+			assignments[id] = client.calibrated(c)
+		}
+
+		err = setPanelColors(client, panelInfo, assignments)
+		if err != nil {
+			return fmt.Errorf("failed to set panel colors: %w", err)
+		}
+		return nil
+	}
+
+	if len(args) == 0 || len(args)%2 != 0 {
+		return usage()
+	}
+
+	assignments := make(map[uint16]RGB)
+	for i := 0; i < len(args); i += 2 {
+		c, err := parseColor(args[i+1], colors)
+		if err != nil {
+			return err
+		}
+
+		for _, field := range strings.Split(args[i], ",") {
+			id, err := strconv.ParseUint(field, 10, 16)
+			if err != nil {
+				return fmt.Errorf("expected panel ID between 0-%d, got %s", math.MaxUint16, field)
+			}
+			assignments[uint16(id)] = client.calibrated(c)
+		}
+	}
+
+	err = setPanelColors(client, panelInfo, assignments)
+	if err != nil {
+		return fmt.Errorf("failed to set panel colors: %w", err)
+	}
+	return nil
+}
+
+// setPanelColors displays a static effect giving the assigned panels their
+// colors. Other panels keep their current color where it can be read back.
+func setPanelColors(client Client, panelInfo *PanelInfo, assignments map[uint16]RGB) error {
+	known := make(map[uint16]bool)
+	for _, panel := range panelInfo.PanelLayout.Layout.PositionData {
+		known[uint16(panel.PanelID)] = true
+	}
+	for id := range assignments {
+		if !known[id] {
+			return fmt.Errorf("no panel with ID %d", id)
+		}
+	}
+
+	colors := currentPanelColors(client, panelInfo)
+	for id, c := range assignments {
+		colors[id] = c
+	}
+
+	var animation []PanelAnimation
+	for _, panel := range lightPanels(panelInfo) {
+		id := uint16(panel.PanelID)
+		c, ok := colors[id]
+		if !ok {
+			continue
+		}
+		animation = append(animation, PanelAnimation{
+			PanelID: id,
+			Frames: []PanelFrame{
+				{Red: c.Red, Green: c.Green, Blue: c.Blue, TransitionTime: panelSetTransitionTime},
+			},
+		})
+	}
+
+	return client.DisplayEffect(Effect{
+		Type:     "static",
+		AnimData: EncodeAnimData(animation),
+	})
+}
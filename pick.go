@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// pickComponents are the color picker's adjustable components, with their
+// ranges and how far the arrow keys move them.
+var pickComponents = []struct {
+	Name string
+	Max  int
+	Step int
+}{
+	{"Hue", 359, 5},
+	{"Saturation", 100, 5},
+	{"Brightness", 100, 5},
+}
+
+func doPickCommand(client Client, args []string) error {
+	if len(args) != 0 {
+		return usageError("usage: picoleaf pick")
+	}
+
+	snapshot, err := client.Snapshot()
+	if err != nil {
+		return fmt.Errorf("failed to get Nanoleaf state: %w", err)
+	}
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	state := panelInfo.State
+	hsv := [3]int{state.Hue.Value, state.Saturation.Value, state.Brightness.Value}
+
+	screen, err := tcell.NewScreen()
+	if err == nil {
+		err = screen.Init()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to start terminal UI: %w", err)
+	}
+
+	chosen, err := runPicker(client, screen, &hsv)
+	screen.Fini()
+	if err != nil {
+		return fmt.Errorf("failed to set color: %w", err)
+	}
+
+	if !chosen {
+		err = client.ApplySnapshot(*snapshot)
+		if err != nil {
+			return fmt.Errorf("failed to restore state: %w", err)
+		}
+		return errors.New("no color picked")
+	}
+	r, g, b := hsvToRGB(hsv[0], hsv[1], hsv[2])
+	fmt.Printf("#%02x%02x%02x\n", r, g, b)
+	return nil
+}
+
+// runPicker shows the color picker until a color is chosen with Enter, or
+// the picker is cancelled. Every change is applied to the Nanoleaf as it's
+// made.
+func runPicker(client Client, screen tcell.Screen, hsv *[3]int) (bool, error) {
+	focus := 0
+	for {
+		drawPicker(screen, *hsv, focus)
+
+		ev, ok := screen.PollEvent().(*tcell.EventKey)
+		if !ok {
+			screen.Sync()
+			continue
+		}
+
+		component := pickComponents[focus]
+		step := component.Step
+		if ev.Modifiers()&tcell.ModShift != 0 {
+			step *= 6
+		}
+		switch {
+		case ev.Key() == tcell.KeyEnter:
+			return true, nil
+		case ev.Key() == tcell.KeyEscape || ev.Key() == tcell.KeyCtrlC || ev.Rune() == 'q':
+			return false, nil
+		case ev.Key() == tcell.KeyUp:
+			focus = (focus + len(pickComponents) - 1) % len(pickComponents)
+			continue
+		case ev.Key() == tcell.KeyDown || ev.Key() == tcell.KeyTab:
+			focus = (focus + 1) % len(pickComponents)
+			continue
+		case ev.Key() == tcell.KeyLeft:
+			step = -step
+		case ev.Key() == tcell.KeyRight:
+		default:
+			continue
+		}
+
+		v := hsv[focus] + step
+		if focus == 0 {
+			v = (v + 360) % 360
+		} else if v < 0 {
+			v = 0
+		} else if v > component.Max {
+			v = component.Max
+		}
+		hsv[focus] = v
+
+		err := client.SetHSV(hsv[0], hsv[1], hsv[2])
+		if err != nil {
+			return false, err
+		}
+	}
+}
+
+// drawPicker renders the color picker with a swatch of the current color.
+func drawPicker(s tcell.Screen, hsv [3]int, focus int) {
+	s.Clear()
+	r, g, b := hsvToRGB(hsv[0], hsv[1], hsv[2])
+	hex := fmt.Sprintf("#%02x%02x%02x", r, g, b)
+
+	swatch := tcell.StyleDefault.Background(tcell.NewRGBColor(int32(r), int32(g), int32(b)))
+	for y := 0; y < 3; y++ {
+		drawText(s, 0, y, swatch, "            ")
+	}
+	drawText(s, 14, 1, tcell.StyleDefault.Bold(true), hex)
+
+	for i, component := range pickComponents {
+		style := tcell.StyleDefault
+		if i == focus {
+			style = style.Reverse(true)
+		}
+		drawText(s, 0, 4+i, style, fmt.Sprintf("%-11s", component.Name))
+		drawText(s, 12, 4+i, tcell.StyleDefault, tuiSlider(hsv[i], 0, component.Max, 30)+fmt.Sprintf(" %d", hsv[i]))
+	}
+
+	drawText(s, 0, 8, tcell.StyleDefault.Bold(true), "↑/↓: choose  ←/→: adjust (shift: faster)  enter: done  q: cancel")
+	s.Show()
+}
@@ -0,0 +1,5 @@
+// Package picoleafpb holds the gRPC service served by `picoleaf serve
+// --grpc`, generated from picoleaf.proto.
+package picoleafpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative picoleaf.proto
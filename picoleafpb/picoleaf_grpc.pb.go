@@ -0,0 +1,336 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: picoleaf.proto
+
+package picoleafpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// PicoleafClient is the client API for Picoleaf service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PicoleafClient interface {
+	// ListDevices returns the names of the configured devices.
+	ListDevices(ctx context.Context, in *ListDevicesRequest, opts ...grpc.CallOption) (*ListDevicesResponse, error)
+	// GetState returns a device's state.
+	GetState(ctx context.Context, in *GetStateRequest, opts ...grpc.CallOption) (*DeviceState, error)
+	// SetState changes the properties set in the request together, then
+	// returns the new state.
+	SetState(ctx context.Context, in *SetStateRequest, opts ...grpc.CallOption) (*DeviceState, error)
+	// ListEffects returns a device's effects and the selected one.
+	ListEffects(ctx context.Context, in *ListEffectsRequest, opts ...grpc.CallOption) (*ListEffectsResponse, error)
+	// SelectEffect selects an effect.
+	SelectEffect(ctx context.Context, in *SelectEffectRequest, opts ...grpc.CallOption) (*SelectEffectResponse, error)
+	// StreamFrames shows frames of panel colors as they arrive, using the
+	// external control protocol. Each frame is acknowledged once it has been
+	// sent. The previously selected effect is restored when the stream ends.
+	StreamFrames(ctx context.Context, opts ...grpc.CallOption) (Picoleaf_StreamFramesClient, error)
+}
+
+type picoleafClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPicoleafClient(cc grpc.ClientConnInterface) PicoleafClient {
+	return &picoleafClient{cc}
+}
+
+func (c *picoleafClient) ListDevices(ctx context.Context, in *ListDevicesRequest, opts ...grpc.CallOption) (*ListDevicesResponse, error) {
+	out := new(ListDevicesResponse)
+	err := c.cc.Invoke(ctx, "/picoleaf.v1.Picoleaf/ListDevices", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *picoleafClient) GetState(ctx context.Context, in *GetStateRequest, opts ...grpc.CallOption) (*DeviceState, error) {
+	out := new(DeviceState)
+	err := c.cc.Invoke(ctx, "/picoleaf.v1.Picoleaf/GetState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *picoleafClient) SetState(ctx context.Context, in *SetStateRequest, opts ...grpc.CallOption) (*DeviceState, error) {
+	out := new(DeviceState)
+	err := c.cc.Invoke(ctx, "/picoleaf.v1.Picoleaf/SetState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *picoleafClient) ListEffects(ctx context.Context, in *ListEffectsRequest, opts ...grpc.CallOption) (*ListEffectsResponse, error) {
+	out := new(ListEffectsResponse)
+	err := c.cc.Invoke(ctx, "/picoleaf.v1.Picoleaf/ListEffects", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *picoleafClient) SelectEffect(ctx context.Context, in *SelectEffectRequest, opts ...grpc.CallOption) (*SelectEffectResponse, error) {
+	out := new(SelectEffectResponse)
+	err := c.cc.Invoke(ctx, "/picoleaf.v1.Picoleaf/SelectEffect", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *picoleafClient) StreamFrames(ctx context.Context, opts ...grpc.CallOption) (Picoleaf_StreamFramesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Picoleaf_ServiceDesc.Streams[0], "/picoleaf.v1.Picoleaf/StreamFrames", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &picoleafStreamFramesClient{stream}
+	return x, nil
+}
+
+type Picoleaf_StreamFramesClient interface {
+	Send(*StreamFramesRequest) error
+	Recv() (*StreamFramesResponse, error)
+	grpc.ClientStream
+}
+
+type picoleafStreamFramesClient struct {
+	grpc.ClientStream
+}
+
+func (x *picoleafStreamFramesClient) Send(m *StreamFramesRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *picoleafStreamFramesClient) Recv() (*StreamFramesResponse, error) {
+	m := new(StreamFramesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PicoleafServer is the server API for Picoleaf service.
+// All implementations must embed UnimplementedPicoleafServer
+// for forward compatibility
+type PicoleafServer interface {
+	// ListDevices returns the names of the configured devices.
+	ListDevices(context.Context, *ListDevicesRequest) (*ListDevicesResponse, error)
+	// GetState returns a device's state.
+	GetState(context.Context, *GetStateRequest) (*DeviceState, error)
+	// SetState changes the properties set in the request together, then
+	// returns the new state.
+	SetState(context.Context, *SetStateRequest) (*DeviceState, error)
+	// ListEffects returns a device's effects and the selected one.
+	ListEffects(context.Context, *ListEffectsRequest) (*ListEffectsResponse, error)
+	// SelectEffect selects an effect.
+	SelectEffect(context.Context, *SelectEffectRequest) (*SelectEffectResponse, error)
+	// StreamFrames shows frames of panel colors as they arrive, using the
+	// external control protocol. Each frame is acknowledged once it has been
+	// sent. The previously selected effect is restored when the stream ends.
+	StreamFrames(Picoleaf_StreamFramesServer) error
+	mustEmbedUnimplementedPicoleafServer()
+}
+
+// UnimplementedPicoleafServer must be embedded to have forward compatible implementations.
+type UnimplementedPicoleafServer struct {
+}
+
+func (UnimplementedPicoleafServer) ListDevices(context.Context, *ListDevicesRequest) (*ListDevicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDevices not implemented")
+}
+func (UnimplementedPicoleafServer) GetState(context.Context, *GetStateRequest) (*DeviceState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetState not implemented")
+}
+func (UnimplementedPicoleafServer) SetState(context.Context, *SetStateRequest) (*DeviceState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetState not implemented")
+}
+func (UnimplementedPicoleafServer) ListEffects(context.Context, *ListEffectsRequest) (*ListEffectsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEffects not implemented")
+}
+func (UnimplementedPicoleafServer) SelectEffect(context.Context, *SelectEffectRequest) (*SelectEffectResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SelectEffect not implemented")
+}
+func (UnimplementedPicoleafServer) StreamFrames(Picoleaf_StreamFramesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamFrames not implemented")
+}
+func (UnimplementedPicoleafServer) mustEmbedUnimplementedPicoleafServer() {}
+
+// UnsafePicoleafServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PicoleafServer will
+// result in compilation errors.
+type UnsafePicoleafServer interface {
+	mustEmbedUnimplementedPicoleafServer()
+}
+
+func RegisterPicoleafServer(s grpc.ServiceRegistrar, srv PicoleafServer) {
+	s.RegisterService(&Picoleaf_ServiceDesc, srv)
+}
+
+func _Picoleaf_ListDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PicoleafServer).ListDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/picoleaf.v1.Picoleaf/ListDevices",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PicoleafServer).ListDevices(ctx, req.(*ListDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Picoleaf_GetState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PicoleafServer).GetState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/picoleaf.v1.Picoleaf/GetState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PicoleafServer).GetState(ctx, req.(*GetStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Picoleaf_SetState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PicoleafServer).SetState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/picoleaf.v1.Picoleaf/SetState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PicoleafServer).SetState(ctx, req.(*SetStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Picoleaf_ListEffects_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEffectsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PicoleafServer).ListEffects(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/picoleaf.v1.Picoleaf/ListEffects",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PicoleafServer).ListEffects(ctx, req.(*ListEffectsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Picoleaf_SelectEffect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SelectEffectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PicoleafServer).SelectEffect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/picoleaf.v1.Picoleaf/SelectEffect",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PicoleafServer).SelectEffect(ctx, req.(*SelectEffectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Picoleaf_StreamFrames_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PicoleafServer).StreamFrames(&picoleafStreamFramesServer{stream})
+}
+
+type Picoleaf_StreamFramesServer interface {
+	Send(*StreamFramesResponse) error
+	Recv() (*StreamFramesRequest, error)
+	grpc.ServerStream
+}
+
+type picoleafStreamFramesServer struct {
+	grpc.ServerStream
+}
+
+func (x *picoleafStreamFramesServer) Send(m *StreamFramesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *picoleafStreamFramesServer) Recv() (*StreamFramesRequest, error) {
+	m := new(StreamFramesRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Picoleaf_ServiceDesc is the grpc.ServiceDesc for Picoleaf service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Picoleaf_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "picoleaf.v1.Picoleaf",
+	HandlerType: (*PicoleafServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListDevices",
+			Handler:    _Picoleaf_ListDevices_Handler,
+		},
+		{
+			MethodName: "GetState",
+			Handler:    _Picoleaf_GetState_Handler,
+		},
+		{
+			MethodName: "SetState",
+			Handler:    _Picoleaf_SetState_Handler,
+		},
+		{
+			MethodName: "ListEffects",
+			Handler:    _Picoleaf_ListEffects_Handler,
+		},
+		{
+			MethodName: "SelectEffect",
+			Handler:    _Picoleaf_SelectEffect_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamFrames",
+			Handler:       _Picoleaf_StreamFrames_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "picoleaf.proto",
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// defaultPlayFPS is the frame rate of animations that don't set one.
+const defaultPlayFPS = 10
+
+// frameAnimation is an animation file for `picoleaf play`. Each frame maps
+// panel IDs to colors; panels missing from a frame keep their color.
+type frameAnimation struct {
+	FPS    float64             `json:"fps"`
+	Loops  *int                `json:"loops"`
+	Frames []map[string]string `json:"frames"`
+}
+
+func doPlayCommand(client Client, colors *ini.Section, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf play <file> [--fps <fps>] [--loops <count>]")
+	}
+
+	fs := flag.NewFlagSet("play", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	fps := fs.Float64("fps", 0, "Frames per second, overriding the file")
+	loops := fs.Int("loops", -1, "Times to play the animation, 0 to loop forever, overriding the file")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 || *fps < 0 {
+		return usage()
+	}
+
+	data, err := ioutil.ReadFile(positional[0])
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var animation frameAnimation
+	err = json.Unmarshal(data, &animation)
+	if err != nil {
+		return fmt.Errorf("failed to parse animation: %w", err)
+	}
+	if len(animation.Frames) == 0 {
+		return errors.New("animation has no frames")
+	}
+
+	if *fps == 0 {
+		*fps = animation.FPS
+	}
+	if *fps == 0 {
+		*fps = defaultPlayFPS
+	}
+	if *loops < 0 {
+		*loops = 1
+		if animation.Loops != nil {
+			*loops = *animation.Loops
+		}
+	}
+
+	interval := time.Duration(float64(time.Second) / *fps)
+	frames, err := animationFrames(animation.Frames, colors, interval)
+	if err != nil {
+		return err
+	}
+
+	i, loop := 0, 0
+	return streamFrames(client, func() ([]SetPanelColor, time.Duration, bool, error) {
+		if i == len(frames) {
+			i, loop = 0, loop+1
+		}
+		if *loops != 0 && loop >= *loops {
+			return nil, 0, false, nil
+		}
+		i++
+		return frames[i-1], interval, true, nil
+	})
+}
+
+// streamFrames streams frames from next, which returns the colors for the
+// next frame, how long to show it, and false once there are no more.
+// Streaming stops early if interrupted. Afterwards the previous state is
+// restored, whether it was an effect or a solid color.
+func streamFrames(client Client, next func() ([]SetPanelColor, time.Duration, bool, error)) (err error) {
+	// Restoring the snapshot undoes the stream, so it isn't recorded.
+	client.History = nil
+	snapshot, err := client.Snapshot()
+	if err != nil {
+		return fmt.Errorf("failed to get Nanoleaf state: %w", err)
+	}
+
+	stream, err := client.OpenStream()
+	if err != nil {
+		return fmt.Errorf("failed to start external control: %w", err)
+	}
+	defer stream.Close()
+	defer func() {
+		restoreErr := client.ApplySnapshot(*snapshot)
+		if err == nil && restoreErr != nil {
+			err = fmt.Errorf("failed to restore state: %w", restoreErr)
+		}
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	// Frames are paced against a deadline rather than slept between, so
+	// time spent decoding and sending doesn't accumulate.
+	deadline := time.Now()
+	for {
+		frame, delay, ok, err := next()
+		if err != nil {
+			return fmt.Errorf("failed to read frame: %w", err)
+		}
+		if !ok {
+			return nil
+		}
+
+		for _, p := range frame {
+			stream.SetPanel(p.PanelID, p.Red, p.Green, p.Blue, p.TransitionTime)
+		}
+		err = stream.Flush()
+		if err != nil {
+			return fmt.Errorf("failed to send frame: %w", err)
+		}
+
+		deadline = deadline.Add(delay)
+		select {
+		case <-time.After(time.Until(deadline)):
+		case <-signals:
+			return nil
+		}
+	}
+}
+
+// animationFrames converts animation frames to panel colors, each
+// transitioning over one frame interval.
+func animationFrames(frames []map[string]string, colors *ini.Section, interval time.Duration) ([][]SetPanelColor, error) {
+	transition := uint16(math.Round(interval.Seconds() * 10))
+
+	result := make([][]SetPanelColor, len(frames))
+	for i, frame := range frames {
+		for key, name := range frame {
+			id, err := strconv.ParseUint(key, 10, 16)
+			if err != nil {
+				return nil, fmt.Errorf("frame %d: expected panel ID between 0-%d, got %s", i+1, math.MaxUint16, key)
+			}
+			c, err := parseColor(name, colors)
+			if err != nil {
+				return nil, fmt.Errorf("frame %d: %v", i+1, err)
+			}
+			result[i] = append(result[i], SetPanelColor{
+				PanelID:        uint16(id),
+				Red:            c.Red,
+				Green:          c.Green,
+				Blue:           c.Blue,
+				TransitionTime: transition,
+			})
+		}
+	}
+	return result, nil
+}
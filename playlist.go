@@ -0,0 +1,204 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// Playlist is a sequence of scenes, presets, effects, and colors to step
+// through.
+type Playlist struct {
+	Loop  bool
+	Steps []PlaylistStep
+}
+
+// PlaylistStep shows one scene, preset, effect, or color for Duration,
+// fading to it over Fade where the device allows.
+type PlaylistStep struct {
+	Kind     string
+	Name     string
+	Duration time.Duration
+	Fade     time.Duration
+}
+
+// playlistKinds are the things a playlist step can show.
+var playlistKinds = []string{"scene", "preset", "effect", "color"}
+
+// ParsePlaylist parses a playlist written in a small subset of YAML:
+//
+//	# Evening lights, forever.
+//	loop: true
+//	fade: 5s
+//
+//	steps:
+//	  - scene: dinner
+//	    duration: 1h
+//	  - effect: Northern Lights
+//	    duration: 30m
+//	  - color: warmwhite
+//	    duration: 10m
+//	    fade: 1m
+//
+// Each step shows one scene, preset, effect, or color for its duration.
+// Steps fade in over their `fade`, or the top-level one; effects always
+// switch immediately. Playlists loop unless `loop` is false. Comments start
+// with `#`.
+func ParsePlaylist(r io.Reader) (*Playlist, error) {
+	playlist := Playlist{Loop: true}
+	var fade time.Duration
+	steps, err := parseListDocument(r, "steps", func(key string, value string) error {
+		var err error
+		switch key {
+		case "loop":
+			playlist.Loop, err = strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("expected `loop: true` or `loop: false`")
+			}
+		case "fade":
+			fade, err = time.ParseDuration(value)
+			if err != nil || fade < 0 {
+				return fmt.Errorf("expected a fade time like 5s, got %s", value)
+			}
+		default:
+			return fmt.Errorf("unknown key %q", key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, fields := range steps {
+		step, err := playlistStep(fields, fade)
+		if err != nil {
+			return nil, validationErrorf("step %d: %v", i+1, err)
+		}
+		playlist.Steps = append(playlist.Steps, step)
+	}
+	if len(playlist.Steps) == 0 {
+		return nil, validationErrorf("playlist has no steps")
+	}
+	return &playlist, nil
+}
+
+// playlistStep builds a step from its parsed fields.
+func playlistStep(fields map[string]string, fade time.Duration) (PlaylistStep, error) {
+	step := PlaylistStep{Fade: fade}
+	for _, kind := range playlistKinds {
+		if name, ok := fields[kind]; ok {
+			if step.Kind != "" {
+				return step, fmt.Errorf("expected only one of %s", strings.Join(playlistKinds, ", "))
+			}
+			step.Kind, step.Name = kind, name
+			delete(fields, kind)
+		}
+	}
+	if step.Kind == "" {
+		return step, fmt.Errorf("expected one of %s", strings.Join(playlistKinds, ", "))
+	}
+
+	var err error
+	step.Duration, err = time.ParseDuration(fields["duration"])
+	if err != nil || step.Duration <= 0 {
+		return step, fmt.Errorf("expected a duration like 10m, got %q", fields["duration"])
+	}
+	delete(fields, "duration")
+
+	if value, ok := fields["fade"]; ok {
+		step.Fade, err = time.ParseDuration(value)
+		if err != nil || step.Fade < 0 {
+			return step, fmt.Errorf("expected a fade time like 5s, got %s", value)
+		}
+		delete(fields, "fade")
+	}
+
+	for key := range fields {
+		return step, fmt.Errorf("unknown key %q", key)
+	}
+	return step, nil
+}
+
+func doPlaylistCommand(client Client, cfg *ini.File, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf playlist run <file> [--once]")
+	}
+
+	if len(args) < 1 || args[0] != "run" {
+		return usage()
+	}
+
+	fs := flag.NewFlagSet("playlist run", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	once := fs.Bool("once", false, "Play through once, even if the playlist loops")
+	positional, err := parseArgs(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usage()
+	}
+
+	f, err := os.Open(positional[0])
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	playlist, err := ParsePlaylist(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to parse playlist: %w", err)
+	}
+
+	for {
+		for _, step := range playlist.Steps {
+			if client.Verbose {
+				fmt.Printf("%s %s for %s\n", step.Kind, step.Name, step.Duration)
+			}
+			err := playStep(client, cfg, step)
+			if err != nil {
+				return fmt.Errorf("failed to show %s %s: %w", step.Kind, step.Name, err)
+			}
+			time.Sleep(step.Duration)
+		}
+		if *once || !playlist.Loop {
+			return nil
+		}
+	}
+}
+
+// playStep shows a playlist step, fading to it if possible.
+func playStep(client Client, cfg *ini.File, step PlaylistStep) error {
+	client.Duration = step.Fade
+
+	switch step.Kind {
+	case "scene":
+		scene, err := LoadScenes()
+		if err != nil {
+			return err
+		}
+		if _, ok := scene[step.Name]; !ok {
+			return validationErrorf("unknown scene: %s", step.Name)
+		}
+		return applyScene(cfg, scene[step.Name], step.Fade)
+	case "preset":
+		preset, err := cfg.GetSection(presetSectionPrefix + step.Name)
+		if err != nil {
+			return validationErrorf("unknown preset: %s", step.Name)
+		}
+		return applyPreset(client, preset, cfg.Section("colors"))
+	case "effect":
+		return client.SelectEffect(step.Name)
+	default:
+		c, err := parseColor(step.Name, cfg.Section("colors"))
+		if err != nil {
+			return err
+		}
+		return client.SetRGB(int(c.Red), int(c.Green), int(c.Blue))
+	}
+}
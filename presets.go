@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// presetSectionPrefix prefixes the names of preset config sections.
+const presetSectionPrefix = "preset "
+
+func doPresetCommand(client Client, cfg *ini.File, args []string) error {
+	args, err := parseStateFlags(&client, "preset", args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return usageError("usage: picoleaf preset [--fade <duration>] [<name>]")
+	}
+
+	if len(args) == 0 {
+		for _, section := range cfg.Sections() {
+			if strings.HasPrefix(section.Name(), presetSectionPrefix) {
+				fmt.Println(strings.TrimPrefix(section.Name(), presetSectionPrefix))
+			}
+		}
+		return nil
+	}
+
+	preset, err := cfg.GetSection(presetSectionPrefix + args[0])
+	if err != nil {
+		return fmt.Errorf("unknown preset: %v", args[0])
+	}
+
+	err = applyPreset(client, preset, cfg.Section("colors"))
+	if err != nil {
+		return fmt.Errorf("failed to apply preset: %w", err)
+	}
+	return nil
+}
+
+// applyPreset sets the state described by a preset section. A preset sets
+// at most one of effect, color, ct, or hue and sat, plus optionally
+// brightness and on.
+func applyPreset(client Client, preset *ini.Section, colors *ini.Section) error {
+	modes := 0
+	for _, key := range []string{"effect", "color", "ct", "hue"} {
+		if preset.HasKey(key) {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return validationErrorf("preset sets more than one of effect, color, ct, and hue")
+	}
+
+	intKey := func(key string, min, max int) (int, error) {
+		v, err := preset.Key(key).Int()
+		if err != nil || v < min || v > max {
+			return 0, validationErrorf("%s must be an integer %d-%d", key, min, max)
+		}
+		return v, nil
+	}
+
+	if preset.HasKey("on") && !preset.Key("on").MustBool(true) {
+		return client.Off()
+	}
+
+	// Everything but the effect is set at once, so it fades together.
+	var state State
+	switch {
+	case preset.HasKey("effect"):
+		err := client.SelectEffect(preset.Key("effect").String())
+		if err != nil {
+			return err
+		}
+	case preset.HasKey("color"):
+		c, err := parseColor(preset.Key("color").String(), colors)
+		if err != nil {
+			return err
+		}
+		state = client.rgbState(int(c.Red), int(c.Green), int(c.Blue))
+	case preset.HasKey("ct"):
+		value := preset.Key("ct").String()
+		ct, ok := namedTemperatures[strings.ToLower(value)]
+		if !ok {
+			var err error
+			ct, err = strconv.Atoi(value)
+			if err != nil || ct <= 0 {
+				return validationErrorf("ct must be warm, neutral, cool, daylight, or a positive integer")
+			}
+		}
+		state.ColorTemperature = &ColorTemperatureProperty{Value: ct}
+	case preset.HasKey("hue"):
+		hue, err := intKey("hue", 0, 360)
+		if err != nil {
+			return err
+		}
+		sat := 100
+		if preset.HasKey("sat") {
+			sat, err = intKey("sat", 0, 100)
+			if err != nil {
+				return err
+			}
+		}
+		state.Hue = &HueProperty{Value: hue}
+		state.Saturation = &SaturationProperty{Value: sat}
+	}
+
+	if preset.HasKey("brightness") {
+		brightness, err := intKey("brightness", 0, 100)
+		if err != nil {
+			return err
+		}
+		state.Brightness = &BrightnessProperty{Value: brightness}
+	}
+	if preset.HasKey("on") {
+		state.On = &OnProperty{true}
+	}
+	if state == (State{}) {
+		return nil
+	}
+	return client.SetState(state)
+}
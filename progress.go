@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// progressTransitionTime is the transition between progress updates, in
+// tenths of a second.
+const progressTransitionTime = 5
+
+func doProgressCommand(client Client, colors *ini.Section, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf progress [--color <color>] [--background <color>] <0-100|->")
+	}
+
+	fs := flag.NewFlagSet("progress", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	colorName := fs.String("color", "lime", "Color of the filled part")
+	backgroundName := fs.String("background", "black", "Color of the empty part")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return usage()
+	}
+
+	fg, err := parseColor(*colorName, colors)
+	if err != nil {
+		return err
+	}
+	bg, err := parseColor(*backgroundName, colors)
+	if err != nil {
+		return err
+	}
+
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	panels := panelsLeftToRight(lightPanels(panelInfo))
+	fg = client.calibrated(fg)
+	bg = client.calibrated(bg)
+
+	show := func(percent float64) error {
+		err := client.DisplayEffect(Effect{
+			Type:     "static",
+			AnimData: EncodeAnimData(progressAnimation(panels, percent, fg, bg)),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to display progress: %w", err)
+		}
+		return nil
+	}
+
+	if args[0] != "-" {
+		percent, err := parseProgress(args[0])
+		if err != nil {
+			return err
+		}
+		return show(percent)
+	}
+
+	// Read one progress value per line until EOF.
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		percent, err := parseProgress(line)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			continue
+		}
+		err = show(percent)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseProgress parses a percentage between 0 and 100, with an optional
+// trailing percent sign.
+func parseProgress(s string) (float64, error) {
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil || percent < 0 || percent > 100 {
+		return 0, fmt.Errorf("progress must be a number 0-100, got %q", s)
+	}
+	return percent, nil
+}
+
+// progressAnimation fills the panels in order, blending the panel at the
+// boundary between the fill and background colors.
+func progressAnimation(panels []PanelPosition, percent float64, fg RGB, bg RGB) []PanelAnimation {
+	filled := percent / 100 * float64(len(panels))
+
+	animation := make([]PanelAnimation, len(panels))
+	for i, panel := range panels {
+		t := math.Max(0, math.Min(1, filled-float64(i)))
+		c := blend(bg, fg, t)
+		animation[i] = PanelAnimation{
+			PanelID: uint16(panel.PanelID),
+			Frames: []PanelFrame{
+				{Red: c.Red, Green: c.Green, Blue: c.Blue, TransitionTime: progressTransitionTime},
+			},
+		}
+	}
+	return animation
+}
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// queueRetryInterval is how often `queue flush --wait` retries while the
+// device is unreachable.
+const queueRetryInterval = 5 * time.Second
+
+// queueSupersedable are the API paths whose queued writes are replaced by
+// later writes of the same properties, rather than all being sent.
+var queueSupersedable = map[string]bool{
+	"state":          true,
+	"effects/select": true,
+}
+
+// QueuedRequest is a state change saved while its device was unreachable.
+type QueuedRequest struct {
+	Time   time.Time       `json:"time"`
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// properties returns the top-level keys of the request's body, and whether
+// any of them are changed relative to their current value.
+func (r QueuedRequest) properties() (map[string]bool, bool) {
+	var body map[string]json.RawMessage
+	json.Unmarshal(r.Body, &body)
+
+	properties := map[string]bool{}
+	relative := false
+	for key, value := range body {
+		properties[key] = true
+		var property struct {
+			Increment *int `json:"increment"`
+		}
+		if json.Unmarshal(value, &property) == nil && property.Increment != nil {
+			relative = true
+		}
+	}
+	return properties, relative
+}
+
+// equal reports whether two queued requests are the same request.
+func (r QueuedRequest) equal(other QueuedRequest) bool {
+	return r.Time.Equal(other.Time) && r.Method == other.Method && r.Path == other.Path && bytes.Equal(r.Body, other.Body)
+}
+
+// supersedes reports whether sending r makes sending an earlier request
+// pointless, because r sets everything it did.
+func (r QueuedRequest) supersedes(earlier QueuedRequest) bool {
+	if r.Method != earlier.Method || r.Path != earlier.Path || !queueSupersedable[r.Path] {
+		return false
+	}
+	properties, relative := r.properties()
+	if relative {
+		return false
+	}
+	earlierProperties, _ := earlier.properties()
+	for key := range earlierProperties {
+		if !properties[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// RequestQueue saves a device's state changes while it's unreachable, to be
+// sent in order once it's back.
+type RequestQueue struct {
+	// Device is the configured device name, or "" for the default device.
+	Device string
+}
+
+// queuePath returns the path of the saved queues.
+func queuePath() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "queue.json"), nil
+}
+
+// LoadQueues returns the queued requests, oldest first, keyed by configured
+// device name.
+func LoadQueues() (map[string][]QueuedRequest, error) {
+	path, err := queuePath()
+	if err != nil {
+		return nil, err
+	}
+
+	queues := map[string][]QueuedRequest{}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return queues, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &queues)
+	return queues, err
+}
+
+// SaveQueues replaces the queued requests.
+func SaveQueues(queues map[string][]QueuedRequest) error {
+	path, err := queuePath()
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	for device, requests := range queues {
+		if len(requests) == 0 {
+			delete(queues, device)
+		}
+	}
+	data, err := json.MarshalIndent(queues, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// Requests returns the device's queued requests, oldest first.
+func (q *RequestQueue) Requests() ([]QueuedRequest, error) {
+	queues, err := LoadQueues()
+	if err != nil {
+		return nil, err
+	}
+	return queues[q.Device], nil
+}
+
+// Add queues a request, dropping any queued requests it supersedes.
+func (q *RequestQueue) Add(request QueuedRequest) error {
+	queues, err := LoadQueues()
+	if err != nil {
+		return err
+	}
+
+	var requests []QueuedRequest
+	for _, queued := range queues[q.Device] {
+		if !request.supersedes(queued) {
+			requests = append(requests, queued)
+		}
+	}
+	queues[q.Device] = append(requests, request)
+	return SaveQueues(queues)
+}
+
+// Clear drops the device's queued requests.
+func (q *RequestQueue) Clear() error {
+	queues, err := LoadQueues()
+	if err != nil {
+		return err
+	}
+	delete(queues, q.Device)
+	return SaveQueues(queues)
+}
+
+// Flush sends the device's queued requests in order, returning how many
+// were sent. It stops at the first request that fails because the device
+// is unreachable, keeping it and the rest queued. Requests the device
+// rejects are dropped, since sending them again won't help.
+func (q *RequestQueue) Flush(client Client) (int, error) {
+	requests, err := q.Requests()
+	if err != nil || len(requests) == 0 {
+		return 0, err
+	}
+
+	client.Queue = nil
+	sent := 0
+	handled := map[int]bool{}
+	var flushErr error
+	for i, request := range requests {
+		var body []byte
+		if len(request.Body) > 0 {
+			body = request.Body
+		}
+		_, err := client.Do(request.Method, request.Path, body)
+		if errors.Is(err, ErrNetwork) {
+			flushErr = err
+			break
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: dropped queued %s %s: %v\n", request.Method, request.Path, err)
+		} else {
+			sent++
+		}
+		handled[i] = true
+	}
+
+	// Another process may have queued requests while these were sent, so
+	// only the handled ones are removed.
+	queues, err := LoadQueues()
+	if err != nil {
+		return sent, err
+	}
+	var remaining []QueuedRequest
+	for _, queued := range queues[q.Device] {
+		done := false
+		for i := range handled {
+			if queued.equal(requests[i]) {
+				done = true
+				break
+			}
+		}
+		if !done {
+			remaining = append(remaining, queued)
+		}
+	}
+	queues[q.Device] = remaining
+	err = SaveQueues(queues)
+	if err != nil {
+		return sent, err
+	}
+	return sent, flushErr
+}
+
+// flushQueue sends the client's queued requests before running a command,
+// so they stay in order with the command's changes.
+func flushQueue(client Client) error {
+	sent, err := client.Queue.Flush(client)
+	if err != nil && !errors.Is(err, ErrNetwork) {
+		return fmt.Errorf("failed to flush queue: %w", err)
+	}
+	if sent > 0 {
+		// On stderr, so it doesn't mix with the command's own output.
+		fmt.Fprintf(os.Stderr, "Sent %d queued change(s)\n", sent)
+	}
+	return nil
+}
+
+// reportQueued reports whether err is a change being queued, and if so
+// prints a notice to stderr. A queued change isn't a failure, since it will
+// be sent once Nanoleaf is back.
+func reportQueued(err error) bool {
+	var queued *QueuedError
+	if !errors.As(err, &queued) {
+		return false
+	}
+	fmt.Fprintln(os.Stderr, queued)
+	return true
+}
+
+// queueable reports whether a request is a lasting state change, worth
+// sending later. Reads, previews and external control setups aren't, since
+// their results are needed now.
+func queueable(method string, path string, body []byte) bool {
+	if method != http.MethodPut {
+		return false
+	}
+
+	switch path {
+	case "state", "effects/select":
+		return true
+	case "effects":
+		var request struct {
+			Write struct {
+				Command string `json:"command"`
+			} `json:"write"`
+		}
+		json.Unmarshal(body, &request)
+		switch request.Write.Command {
+		case "add", "delete", "rename":
+			return true
+		}
+	}
+	return false
+}
+
+// queueRequest saves a request that failed because the device was
+// unreachable, reporting whether it was queued.
+func (c Client) queueRequest(method string, path string, body []byte) bool {
+	if c.Queue == nil || !queueable(method, path, body) {
+		return false
+	}
+	err := c.Queue.Add(QueuedRequest{
+		Time:   time.Now(),
+		Method: method,
+		Path:   path,
+		Body:   json.RawMessage(body),
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "warning: failed to queue change:", err)
+		return false
+	}
+	return true
+}
+
+func doQueueCommand(client Client, device string, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf queue [list]\n       picoleaf queue flush [--wait <duration>]\n       picoleaf queue clear")
+	}
+
+	queue := &RequestQueue{Device: device}
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+	switch args[0] {
+	case "list":
+		if len(args) != 1 {
+			return usage()
+		}
+		requests, err := queue.Requests()
+		if err != nil {
+			return fmt.Errorf("failed to read queue: %w", err)
+		}
+		if ok, err := printFormatted(requests); ok || err != nil {
+			return err
+		}
+		for _, request := range requests {
+			var body bytes.Buffer
+			json.Compact(&body, request.Body)
+			fmt.Printf("%s  %s %s %s\n", request.Time.Format("2006-01-02 15:04:05"), request.Method, request.Path, body.String())
+		}
+	case "flush":
+		fs := flag.NewFlagSet("queue flush", flag.ContinueOnError)
+		wait := fs.Duration("wait", 0, "Keep retrying while Nanoleaf is unreachable, for up to this long")
+		positional, err := parseArgs(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		if len(positional) != 0 {
+			return usage()
+		}
+
+		deadline := time.Now().Add(*wait)
+		total := 0
+		for {
+			sent, err := queue.Flush(client)
+			total += sent
+			if errors.Is(err, ErrNetwork) && time.Now().Add(queueRetryInterval).Before(deadline) {
+				time.Sleep(queueRetryInterval)
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to flush queue: %w", err)
+			}
+			break
+		}
+		fmt.Printf("Sent %d queued change(s)\n", total)
+	case "clear":
+		if len(args) != 1 {
+			return usage()
+		}
+		err := queue.Clear()
+		if err != nil {
+			return fmt.Errorf("failed to clear queue: %w", err)
+		}
+	default:
+		return usage()
+	}
+	return nil
+}
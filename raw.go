@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+func doRawCommand(client Client, args []string) error {
+	if len(args) != 2 && len(args) != 3 {
+		return usageError("usage: picoleaf raw <method> <path> [<json>|-]")
+	}
+
+	method := strings.ToUpper(args[0])
+	path := strings.TrimPrefix(args[1], "/")
+
+	var body []byte
+	if len(args) == 3 {
+		if args[2] == "-" {
+			var err error
+			body, err = ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("failed to read request body: %w", err)
+			}
+		} else {
+			body = []byte(args[2])
+		}
+	}
+
+	res, err := client.Do(method, path, body)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+
+	if len(res) > 0 {
+		fmt.Println(res)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// namedRegions select panels by the position of their centers within the
+// layout's bounding box, normalized so both axes run from 0 to 1 (left to
+// right, bottom to top).
+var namedRegions = map[string]func(x, y float64) bool{
+	"all":         func(x, y float64) bool { return true },
+	"top":         func(x, y float64) bool { return y >= 2.0/3 },
+	"bottom":      func(x, y float64) bool { return y <= 1.0/3 },
+	"left":        func(x, y float64) bool { return x <= 1.0/3 },
+	"right":       func(x, y float64) bool { return x >= 2.0/3 },
+	"center":      func(x, y float64) bool { return x > 1.0/3 && x < 2.0/3 && y > 1.0/3 && y < 2.0/3 },
+	"top-half":    func(x, y float64) bool { return y >= 0.5 },
+	"bottom-half": func(x, y float64) bool { return y <= 0.5 },
+	"left-half":   func(x, y float64) bool { return x <= 0.5 },
+	"right-half":  func(x, y float64) bool { return x >= 0.5 },
+}
+
+// regionPanelIDs returns the IDs of the panels in a region, in order. A
+// region is either one of the named regions or a comma-separated list of
+// coordinate ranges in layout units, such as `x=0:300,y=200:`.
+func regionPanelIDs(panelInfo *PanelInfo, region string) ([]uint16, error) {
+	polygons := layoutPolygons(panelInfo)
+	if len(polygons) == 0 {
+		return nil, fmt.Errorf("layout has no drawable panels")
+	}
+
+	min, max := bounds(polygons)
+	normalize := func(v, lo, hi float64) float64 {
+		if hi == lo {
+			return 0.5
+		}
+		return (v - lo) / (hi - lo)
+	}
+
+	selector, ok := namedRegions[region]
+	if !ok {
+		var err error
+		selector, err = parseCoordinateRegion(region)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var ids []uint16
+	for _, id := range sortedPanelIDs(polygons) {
+		c := centroid(polygons[id])
+		x, y := c.X, c.Y
+		if ok {
+			x = normalize(c.X, min.X, max.X)
+			y = normalize(c.Y, min.Y, max.Y)
+		}
+		if selector(x, y) {
+			ids = append(ids, uint16(id))
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no panels in region %q", region)
+	}
+	return ids, nil
+}
+
+// parseCoordinateRegion parses ranges like `x=0:300,y=200:` into a selector
+// over layout coordinates. Either end of a range may be omitted.
+func parseCoordinateRegion(region string) (func(x, y float64) bool, error) {
+	invalid := fmt.Errorf("unknown region %q (expected one of %s, or ranges like x=0:300,y=200:)", region, strings.Join(regionNames(), ", "))
+
+	ranges := map[string][2]float64{
+		"x": {math.Inf(-1), math.Inf(1)},
+		"y": {math.Inf(-1), math.Inf(1)},
+	}
+	for _, part := range strings.Split(region, ",") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, invalid
+		}
+		axis := strings.TrimSpace(kv[0])
+		r, ok := ranges[axis]
+		if !ok {
+			return nil, invalid
+		}
+
+		bounds := strings.SplitN(kv[1], ":", 2)
+		if len(bounds) != 2 {
+			return nil, invalid
+		}
+		for i, b := range bounds {
+			b = strings.TrimSpace(b)
+			if b == "" {
+				continue
+			}
+			v, err := strconv.ParseFloat(b, 64)
+			if err != nil {
+				return nil, invalid
+			}
+			r[i] = v
+		}
+		ranges[axis] = r
+	}
+
+	return func(x, y float64) bool {
+		return x >= ranges["x"][0] && x <= ranges["x"][1] &&
+			y >= ranges["y"][0] && y <= ranges["y"][1]
+	}, nil
+}
+
+// regionNames returns the names of the named regions in order.
+func regionNames() []string {
+	names := make([]string, 0, len(namedRegions))
+	for name := range namedRegions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
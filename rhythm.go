@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// Rhythm module audio sources.
+const (
+	RhythmMicrophone = 0
+	RhythmAux        = 1
+)
+
+// SetRhythmMode sets the Rhythm module's audio source.
+func (c Client) SetRhythmMode(mode int) error {
+	bytes, err := json.Marshal(struct {
+		Mode int `json:"rhythmMode"`
+	}{mode})
+	if err != nil {
+		return err
+	}
+	_, err = c.Put("rhythm/rhythmMode", bytes)
+	return err
+}
+
+func doRhythmCommand(client Client, cfg *ini.File, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf rhythm\n       picoleaf rhythm mode microphone|aux\n       picoleaf rhythm watch [--interval <duration>]")
+	}
+
+	if len(args) == 0 {
+		panelInfo, err := getPanelInfo(client)
+		if err != nil {
+			return err
+		}
+		rhythm := panelInfo.Rhythm
+		if ok, err := printFormatted(rhythm); ok || err != nil {
+			return err
+		}
+
+		if !rhythm.Connected {
+			fmt.Println("Connected:   no")
+			return nil
+		}
+
+		mode := "microphone"
+		if rhythm.Mode == RhythmAux {
+			mode = "aux"
+		}
+		active := "no"
+		if rhythm.Active {
+			active = "yes"
+		}
+		aux := "no"
+		if rhythm.AuxAvailable {
+			aux = "yes"
+		}
+
+		fmt.Println("Connected:   yes")
+		fmt.Println("Active:     ", active)
+		fmt.Println("Mode:       ", mode)
+		fmt.Println("Aux Input:  ", aux)
+		fmt.Println("Firmware:   ", rhythm.FirmwareVersion)
+		fmt.Println("Hardware:   ", rhythm.HardwareVersion)
+		fmt.Printf("Position:    x=%g y=%g o=%g\n", rhythm.Position.X, rhythm.Position.Y, rhythm.Position.O)
+		return nil
+	}
+
+	if args[0] == "watch" {
+		return doRhythmWatchCommand(client, cfg.Section("rhythm"), args[1:])
+	}
+	if args[0] != "mode" || len(args) != 2 {
+		return usage()
+	}
+
+	var mode int
+	switch args[1] {
+	case "microphone", "mic":
+		mode = RhythmMicrophone
+	case "aux":
+		mode = RhythmAux
+	default:
+		return usage()
+	}
+
+	err := client.SetRhythmMode(mode)
+	if err != nil {
+		return fmt.Errorf("failed to set rhythm mode: %w", err)
+	}
+	return nil
+}
+
+// doRhythmWatchCommand switches the Rhythm module to aux input when a cable
+// is plugged in and back to the microphone when it's removed, optionally
+// selecting an effect for each.
+func doRhythmWatchCommand(client Client, settings *ini.Section, args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	interval := fs.Duration("interval", 5*time.Second, "How often to check the aux input")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 || *interval <= 0 {
+		return usageError("usage: picoleaf rhythm watch [--interval <duration>]")
+	}
+
+	first := true
+	var aux bool
+	for ; ; time.Sleep(*interval) {
+		panelInfo, err := client.GetPanelInfo()
+		if err != nil {
+			fmt.Println("error: failed to get Nanoleaf state:", err)
+			continue
+		}
+
+		rhythm := panelInfo.Rhythm
+		if !rhythm.Connected || (!first && rhythm.AuxAvailable == aux) {
+			continue
+		}
+		first = false
+		aux = rhythm.AuxAvailable
+
+		mode, effectKey := RhythmMicrophone, "microphone_effect"
+		if aux {
+			mode, effectKey = RhythmAux, "aux_effect"
+		}
+		if client.Verbose {
+			fmt.Println("aux available:", aux)
+		}
+
+		if rhythm.Mode != mode {
+			err = client.SetRhythmMode(mode)
+			if err != nil {
+				fmt.Println("error: failed to set rhythm mode:", err)
+				continue
+			}
+		}
+
+		if settings.HasKey(effectKey) {
+			err = client.SelectEffect(settings.Key(effectKey).String())
+			if err != nil {
+				fmt.Println("error: failed to select effect:", err)
+			}
+		}
+	}
+}
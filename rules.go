@@ -0,0 +1,614 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/ini.v1"
+)
+
+// Rules is a set of automation rules, with the address to listen on for
+// webhook triggers and the token their callers must send.
+type Rules struct {
+	Listen string
+	Token  string
+	Rules  []Rule
+}
+
+// Rule runs its actions when its trigger fires and all its conditions hold.
+type Rule struct {
+	Name string
+	When RuleTrigger
+	If   []RuleCondition
+	Do   []gestureAction
+}
+
+// RuleTrigger is what makes a rule run: a touch gesture, an event, the
+// state crossing a threshold, a time of day, or a webhook.
+type RuleTrigger struct {
+	Kind string
+
+	Gesture   string        // touch: a gesture, optionally with a panel, like "tap.1234"
+	Event     EventType     // event: the event type
+	Attr      string        // event: the attribute, or "" for any
+	Threshold RuleCondition // state: fires when this starts to hold
+	At        string        // time: HH:MM
+	Path      string        // webhook: the URL path
+}
+
+// RuleCondition is a comparison against a state property, like
+// "brightness > 50", or a time window, like "between 22:00 07:00".
+type RuleCondition struct {
+	Property string
+	Op       string
+	Value    string
+
+	// From and To bound a time window, in minutes since midnight.
+	From, To int
+}
+
+// ruleComparisonOps are the operators allowed in comparisons.
+var ruleComparisonOps = []string{"==", "!=", "<", "<=", ">", ">="}
+
+// ParseRules parses a rules file written in the same subset of YAML as
+// playlists:
+//
+//	listen: :8081
+//	token: s3cret
+//
+//	rules:
+//	  - name: bedtime
+//	    when: time 22:30
+//	    if: on
+//	    do: brightness 20; temp 2700
+//	  - when: touch double-tap
+//	    do: toggle
+//	  - when: state brightness > 90
+//	    if: between 22:00 07:00
+//	    do: brightness 40
+//	  - when: webhook /doorbell
+//	    do: preset alert; url https://example.com/rang
+//
+// Triggers are `touch <gesture>`, `event <type> [<attribute>]`, `state
+// <comparison>`, `time HH:MM` and `webhook <path>`. Webhook callers must
+// send the token, which defaults to the one in the [webhook] section of
+// cfg. Conditions are comparisons and `between HH:MM HH:MM`, joined with
+// `and`. Actions are those allowed for gestures, plus `color <color>`,
+// `notify [<color>]` and `url <url>`, which sends a POST request,
+// separated by `;`.
+func ParseRules(r io.Reader, cfg *ini.File) (*Rules, error) {
+	var rules Rules
+	items, err := parseListDocument(r, "rules", func(key string, value string) error {
+		switch key {
+		case "listen":
+			rules.Listen = value
+		case "token":
+			rules.Token = value
+		default:
+			return fmt.Errorf("unknown key %q", key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rules.Token == "" {
+		rules.Token = cfg.Section("webhook").Key("token").String()
+	}
+
+	for i, fields := range items {
+		rule, err := parseRule(fields, cfg)
+		if err != nil {
+			return nil, validationErrorf("rule %d: %v", i+1, err)
+		}
+		if rule.When.Kind == "webhook" && rules.Listen == "" {
+			return nil, validationErrorf("rule %d: webhook triggers need a `listen` address", i+1)
+		}
+		if rule.When.Kind == "webhook" && rules.Token == "" {
+			return nil, validationErrorf("rule %d: webhook triggers need a `token`, or token in the [webhook] section", i+1)
+		}
+		rules.Rules = append(rules.Rules, rule)
+	}
+	if len(rules.Rules) == 0 {
+		return nil, validationErrorf("no rules")
+	}
+	return &rules, nil
+}
+
+// parseRule builds a rule from its parsed fields.
+func parseRule(fields map[string]string, cfg *ini.File) (Rule, error) {
+	rule := Rule{Name: fields["name"]}
+	for key := range fields {
+		switch key {
+		case "name", "when", "if", "do":
+		default:
+			return rule, fmt.Errorf("unknown key %q", key)
+		}
+	}
+
+	var err error
+	rule.When, err = parseRuleTrigger(fields["when"])
+	if err != nil {
+		return rule, err
+	}
+
+	if fields["if"] != "" {
+		for _, spec := range strings.Split(fields["if"], " and ") {
+			condition, err := parseRuleCondition(spec)
+			if err != nil {
+				return rule, err
+			}
+			rule.If = append(rule.If, condition)
+		}
+	}
+
+	if fields["do"] == "" {
+		return rule, fmt.Errorf("expected actions in `do`")
+	}
+	for _, spec := range strings.Split(fields["do"], ";") {
+		action, err := parseRuleAction(cfg, strings.TrimSpace(spec))
+		if err != nil {
+			return rule, err
+		}
+		rule.Do = append(rule.Do, action)
+	}
+	return rule, nil
+}
+
+// parseRuleTrigger parses the `when` of a rule.
+func parseRuleTrigger(spec string) (RuleTrigger, error) {
+	fields := strings.Fields(spec)
+	if len(fields) < 2 {
+		return RuleTrigger{}, fmt.Errorf("expected a trigger in `when`, like `touch tap` or `time 07:00`")
+	}
+	trigger := RuleTrigger{Kind: fields[0]}
+
+	switch trigger.Kind {
+	case "touch":
+		gesture := strings.SplitN(fields[1], ".", 2)
+		if _, err := parseGesture(gesture[0]); err != nil || len(fields) != 2 {
+			return trigger, fmt.Errorf("expected `touch <gesture>`, got %q", spec)
+		}
+		if len(gesture) == 2 {
+			if _, err := strconv.Atoi(gesture[1]); err != nil {
+				return trigger, fmt.Errorf("invalid panel ID %q", gesture[1])
+			}
+		}
+		trigger.Gesture = fields[1]
+	case "event":
+		t, err := ParseEventType(fields[1])
+		if err != nil || len(fields) > 3 {
+			return trigger, fmt.Errorf("expected `event <type> [<attribute>]`, got %q", spec)
+		}
+		trigger.Event = t
+		if len(fields) == 3 {
+			trigger.Attr = fields[2]
+			known := false
+			for _, name := range eventAttributeNames[t] {
+				known = known || name == trigger.Attr
+			}
+			if !known {
+				return trigger, fmt.Errorf("unknown %s attribute %q", t, trigger.Attr)
+			}
+		}
+	case "state":
+		condition, err := parseRuleComparison(strings.Join(fields[1:], " "))
+		if err != nil {
+			return trigger, err
+		}
+		trigger.Threshold = condition
+	case "time":
+		if _, err := time.Parse("15:04", fields[1]); err != nil || len(fields) != 2 {
+			return trigger, fmt.Errorf("expected `time HH:MM`, got %q", spec)
+		}
+		trigger.At = fields[1]
+	case "webhook":
+		if !strings.HasPrefix(fields[1], "/") || len(fields) != 2 {
+			return trigger, fmt.Errorf("expected `webhook /<path>`, got %q", spec)
+		}
+		trigger.Path = fields[1]
+	default:
+		return trigger, fmt.Errorf("unknown trigger %q", trigger.Kind)
+	}
+	return trigger, nil
+}
+
+// parseGesture returns the gesture with the given name.
+func parseGesture(name string) (Gesture, error) {
+	for g, n := range gestureNames {
+		if n == name {
+			return g, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown gesture %q", name)
+}
+
+// parseRuleCondition parses a time window or a comparison.
+func parseRuleCondition(spec string) (RuleCondition, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 || fields[0] != "between" {
+		return parseRuleComparison(spec)
+	}
+
+	condition := RuleCondition{Property: "between"}
+	if len(fields) != 3 {
+		return condition, fmt.Errorf("expected `between HH:MM HH:MM`, got %q", spec)
+	}
+	for i, field := range fields[1:] {
+		t, err := time.Parse("15:04", field)
+		if err != nil {
+			return condition, fmt.Errorf("expected `between HH:MM HH:MM`, got %q", spec)
+		}
+		minutes := t.Hour()*60 + t.Minute()
+		if i == 0 {
+			condition.From = minutes
+		} else {
+			condition.To = minutes
+		}
+	}
+	return condition, nil
+}
+
+// parseRuleComparison parses a comparison like "brightness > 50" or
+// "effect == Northern Lights". A bare "on" or "off" checks the power.
+func parseRuleComparison(spec string) (RuleCondition, error) {
+	switch strings.TrimSpace(spec) {
+	case "on":
+		return RuleCondition{Property: "on", Op: "==", Value: "true"}, nil
+	case "off":
+		return RuleCondition{Property: "on", Op: "==", Value: "false"}, nil
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) < 3 {
+		return RuleCondition{}, fmt.Errorf("expected a comparison like `brightness > 50`, got %q", spec)
+	}
+	condition := RuleCondition{Property: fields[0], Op: fields[1], Value: strings.Join(fields[2:], " ")}
+
+	if watchedProperty(condition.Property) == nil {
+		return condition, fmt.Errorf("unknown property %q", condition.Property)
+	}
+	valid := false
+	for _, op := range ruleComparisonOps {
+		valid = valid || op == condition.Op
+	}
+	if !valid {
+		return condition, fmt.Errorf("unknown operator %q", condition.Op)
+	}
+	return condition, nil
+}
+
+// watchedProperty returns the watched property with the given name, or nil.
+func watchedProperty(name string) func(*PanelInfo) interface{} {
+	for _, property := range watchedProperties {
+		if property.Name == name {
+			return property.Value
+		}
+	}
+	return nil
+}
+
+// Holds reports whether the condition holds for panelInfo at now. Values
+// are compared as numbers when both sides are numbers, and as strings
+// otherwise.
+func (c RuleCondition) Holds(panelInfo *PanelInfo, now time.Time) bool {
+	if c.Property == "between" {
+		minutes := now.Hour()*60 + now.Minute()
+		if c.From <= c.To {
+			return minutes >= c.From && minutes < c.To
+		}
+		return minutes >= c.From || minutes < c.To
+	}
+
+	value := fmt.Sprint(watchedProperty(c.Property)(panelInfo))
+	a, errA := strconv.ParseFloat(value, 64)
+	b, errB := strconv.ParseFloat(c.Value, 64)
+	if errA != nil || errB != nil {
+		switch c.Op {
+		case "==":
+			return value == c.Value
+		case "!=":
+			return value != c.Value
+		}
+		return false
+	}
+
+	switch c.Op {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	default:
+		return a >= b
+	}
+}
+
+// ruleURLTimeout limits how long a `url` action may take.
+const ruleURLTimeout = 10 * time.Second
+
+// parseRuleAction parses an action, allowing `color`, `notify` and `url` in
+// addition to the gesture actions.
+func parseRuleAction(cfg *ini.File, spec string) (gestureAction, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty action")
+	}
+
+	switch fields[0] {
+	case "color":
+		c, err := parseColor(strings.Join(fields[1:], " "), cfg.Section("colors"))
+		if err != nil {
+			return nil, err
+		}
+		return func(client Client) error {
+			return client.SetRGB(int(c.Red), int(c.Green), int(c.Blue))
+		}, nil
+	case "notify":
+		name := strings.Join(fields[1:], " ")
+		if name == "" {
+			name = "red"
+		}
+		c, err := parseColor(name, cfg.Section("colors"))
+		if err != nil {
+			return nil, err
+		}
+		return func(client Client) error {
+			return notify(client, c, 3, time.Second, notifyPatterns["flash"], nil)
+		}, nil
+	case "url":
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("expected `url <url>`, got %q", spec)
+		}
+		url := fields[1]
+		return func(client Client) error {
+			httpClient := http.Client{Timeout: ruleURLTimeout}
+			res, err := httpClient.Post(url, "", nil)
+			if err != nil {
+				return err
+			}
+			res.Body.Close()
+			if res.StatusCode < 200 || res.StatusCode > 299 {
+				return fmt.Errorf("POST %s: %s", url, res.Status)
+			}
+			return nil
+		}, nil
+	}
+	return parseGestureAction(cfg, spec)
+}
+
+// ruleSignal is something that happened which may trigger rules. Match
+// reports whether a touch, event, time or webhook trigger fires; state
+// triggers are checked against PanelInfo instead. Context, if set, is the
+// trace of whatever sent the signal.
+type ruleSignal struct {
+	Kind      string
+	Match     func(RuleTrigger) bool
+	PanelInfo *PanelInfo
+	Context   context.Context
+}
+
+func doRulesCommand(client Client, cfg *ini.File, args []string) error {
+	if len(args) != 2 || args[0] != "run" {
+		return usageError("usage: picoleaf rules run <file>")
+	}
+
+	f, err := os.Open(args[1])
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	rules, err := ParseRules(f, cfg)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to parse rules: %w", err)
+	}
+
+	signals := make(chan ruleSignal)
+	kinds := map[string]bool{}
+	for _, rule := range rules.Rules {
+		kinds[rule.When.Kind] = true
+	}
+
+	// The rules stop if watching events or serving webhooks fails.
+	errs := make(chan error, 2)
+	if kinds["touch"] || kinds["event"] || kinds["state"] {
+		go func() {
+			errs <- watchRuleEvents(client, rules, signals)
+		}()
+	}
+	if kinds["time"] {
+		go watchRuleTimes(signals)
+	}
+	if kinds["webhook"] {
+		go func() {
+			errs <- serveRuleWebhooks(rules, signals)
+		}()
+	}
+
+	// State triggers fire when their threshold starts to hold, so they
+	// start from the current state rather than firing immediately.
+	held := make([]bool, len(rules.Rules))
+	if kinds["state"] {
+		panelInfo, err := getPanelInfo(client)
+		if err != nil {
+			return err
+		}
+		for i, rule := range rules.Rules {
+			held[i] = rule.When.Kind == "state" && rule.When.Threshold.Holds(panelInfo, time.Now())
+		}
+	}
+
+	for {
+		var signal ruleSignal
+		select {
+		case signal = <-signals:
+		case err := <-errs:
+			return err
+		}
+
+		for i, rule := range rules.Rules {
+			if rule.When.Kind != signal.Kind {
+				continue
+			}
+			if signal.Kind == "state" {
+				holds := rule.When.Threshold.Holds(signal.PanelInfo, time.Now())
+				fire := holds && !held[i]
+				held[i] = holds
+				if !fire {
+					continue
+				}
+			} else if !signal.Match(rule.When) {
+				continue
+			}
+			runRule(signal.Context, client, i, rule, signal.PanelInfo)
+		}
+	}
+}
+
+// runRule runs a triggered rule's actions if its conditions hold, in a span
+// that's a child of ctx.
+func runRule(ctx context.Context, client Client, index int, rule Rule, panelInfo *PanelInfo) {
+	name := rule.Name
+	if name == "" {
+		name = fmt.Sprintf("rule %d", index+1)
+	}
+
+	var err error
+	ctx, span := startSpan(ctx, "rule "+name)
+	defer func() { endSpan(span, err) }()
+	client.Context = ctx
+
+	now := time.Now()
+	for _, condition := range rule.If {
+		if panelInfo == nil && condition.Property != "between" {
+			panelInfo, err = client.GetPanelInfo()
+			if err != nil {
+				fmt.Printf("error: %s: failed to get Nanoleaf state: %v\n", name, err)
+				return
+			}
+		}
+		if !condition.Holds(panelInfo, now) {
+			return
+		}
+	}
+
+	fmt.Printf("%s %s\n", now.Format("2006-01-02 15:04:05"), name)
+	for _, action := range rule.Do {
+		err = action(client)
+		if err != nil {
+			fmt.Printf("error: %s: %v\n", name, err)
+			return
+		}
+	}
+}
+
+// watchRuleEvents subscribes to the events needed by rules, signaling
+// touches and events as they arrive, and the new state after any change.
+func watchRuleEvents(client Client, rules *Rules, signals chan<- ruleSignal) error {
+	wanted := map[EventType]bool{}
+	for _, rule := range rules.Rules {
+		switch rule.When.Kind {
+		case "touch":
+			wanted[EventTouch] = true
+		case "event":
+			wanted[rule.When.Event] = true
+		case "state":
+			wanted[EventState] = true
+			wanted[EventEffects] = true
+		}
+	}
+	var types []EventType
+	for t := range wanted {
+		types = append(types, t)
+	}
+
+	for {
+		err := client.SubscribeEvents(types, func(event Event) error {
+			for _, item := range event.Items {
+				item := item
+				if event.Type == EventTouch {
+					signals <- ruleSignal{Kind: "touch", Match: func(t RuleTrigger) bool {
+						return t.Gesture == item.Gesture.String() || t.Gesture == item.Gesture.String()+"."+strconv.Itoa(item.PanelID)
+					}}
+					continue
+				}
+				signals <- ruleSignal{Kind: "event", Match: func(t RuleTrigger) bool {
+					return t.Event == event.Type && (t.Attr == "" || t.Attr == event.AttrName(item))
+				}}
+			}
+
+			if event.Type == EventState || event.Type == EventEffects {
+				panelInfo, err := client.GetPanelInfo()
+				if err != nil {
+					fmt.Println("error: failed to get Nanoleaf state:", err)
+					return nil
+				}
+				signals <- ruleSignal{Kind: "state", PanelInfo: panelInfo}
+			}
+			return nil
+		})
+		if !errors.Is(err, ErrNetwork) {
+			return fmt.Errorf("failed to listen for Nanoleaf events: %w", err)
+		}
+		fmt.Println("error: lost connection to Nanoleaf events:", err)
+		time.Sleep(eventReconnectDelay)
+	}
+}
+
+// watchRuleTimes signals the time at the start of every minute.
+func watchRuleTimes(signals chan<- ruleSignal) {
+	for {
+		now := time.Now()
+		time.Sleep(now.Truncate(time.Minute).Add(time.Minute).Sub(now))
+		at := time.Now().Format("15:04")
+		signals <- ruleSignal{Kind: "time", Match: func(t RuleTrigger) bool {
+			return t.At == at
+		}}
+	}
+}
+
+// serveRuleWebhooks signals requests to the webhook paths used by rules, if
+// they carry the rules' token.
+func serveRuleWebhooks(rules *Rules, signals chan<- ruleSignal) error {
+	paths := map[string]bool{}
+	for _, rule := range rules.Rules {
+		if rule.When.Kind == "webhook" {
+			paths[rule.When.Path] = true
+		}
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !webhookAuthorized(r, rules.Token) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if !paths[r.URL.Path] {
+			http.NotFound(w, r)
+			return
+		}
+		path := r.URL.Path
+		// Rules run after the response is sent, so they keep the request's
+		// trace but not its cancellation.
+		ctx := trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(r.Context()))
+		signals <- ruleSignal{Kind: "webhook", Context: ctx, Match: func(t RuleTrigger) bool {
+			return t.Path == path
+		}}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	err := http.ListenAndServe(rules.Listen, traceHandler(handler))
+	return fmt.Errorf("failed to listen for webhooks: %w", err)
+}
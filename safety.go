@@ -0,0 +1,105 @@
+package main
+
+import "math"
+
+// SafetyLimits bound how quickly generated animations may change brightness,
+// to avoid patterns that can trigger photosensitive seizures. Every
+// animation the client displays or streams is passed through them.
+type SafetyLimits struct {
+	// MaxFlashRate is the highest number of flashes per second, where a
+	// flash is a pair of opposing brightness changes.
+	MaxFlashRate float64
+
+	// MaxBrightnessChange is the largest change in brightness per second,
+	// as a fraction of full brightness.
+	MaxBrightnessChange float64
+}
+
+// DefaultSafetyLimits follow the WCAG guideline of at most three flashes
+// per second. Configured limits may only be tighter.
+var DefaultSafetyLimits = SafetyLimits{
+	MaxFlashRate:        3,
+	MaxBrightnessChange: 2,
+}
+
+// flashThreshold is the smallest change in brightness that counts as part
+// of a flash.
+const flashThreshold = 0.1
+
+// Tighten returns limits that are no looser than the defaults.
+func (l SafetyLimits) Tighten() SafetyLimits {
+	if l.MaxFlashRate <= 0 || l.MaxFlashRate > DefaultSafetyLimits.MaxFlashRate {
+		l.MaxFlashRate = DefaultSafetyLimits.MaxFlashRate
+	}
+	if l.MaxBrightnessChange <= 0 || l.MaxBrightnessChange > DefaultSafetyLimits.MaxBrightnessChange {
+		l.MaxBrightnessChange = DefaultSafetyLimits.MaxBrightnessChange
+	}
+	return l
+}
+
+// LimitAnimation lengthens transitions in panel animations that change
+// brightness too quickly. Looping animations are also checked across the
+// transition from their last frame back to their first.
+func (l SafetyLimits) LimitAnimation(panels []PanelAnimation, loop bool) []PanelAnimation {
+	limited := make([]PanelAnimation, len(panels))
+	for i, panel := range panels {
+		frames := make([]PanelFrame, len(panel.Frames))
+		copy(frames, panel.Frames)
+
+		for j := range frames {
+			prev := j - 1
+			if prev < 0 {
+				if !loop || len(frames) < 2 {
+					continue
+				}
+				prev = len(frames) - 1
+			}
+
+			delta := math.Abs(luminance(frames[j].Red, frames[j].Green, frames[j].Blue) -
+				luminance(frames[prev].Red, frames[prev].Green, frames[prev].Blue))
+			min := l.minTransitionTime(delta)
+			if frames[j].TransitionTime < min {
+				frames[j].TransitionTime = min
+			}
+		}
+
+		limited[i] = PanelAnimation{PanelID: panel.PanelID, Frames: frames}
+	}
+	return limited
+}
+
+// LimitFrames lengthens transitions between successive frames for the same
+// panel in a stream of external control frames.
+func (l SafetyLimits) LimitFrames(frames []SetPanelColor) []SetPanelColor {
+	limited := make([]SetPanelColor, len(frames))
+	last := make(map[uint16]SetPanelColor)
+	for i, frame := range frames {
+		if prev, ok := last[frame.PanelID]; ok {
+			delta := math.Abs(luminance(frame.Red, frame.Green, frame.Blue) -
+				luminance(prev.Red, prev.Green, prev.Blue))
+			min := l.minTransitionTime(delta)
+			if frame.TransitionTime < min {
+				frame.TransitionTime = min
+			}
+		}
+		last[frame.PanelID] = frame
+		limited[i] = frame
+	}
+	return limited
+}
+
+// minTransitionTime returns the shortest allowed transition, in tenths of a
+// second, for a change in brightness.
+func (l SafetyLimits) minTransitionTime(delta float64) uint16 {
+	if delta < flashThreshold {
+		return 0
+	}
+
+	seconds := math.Max(1/(2*l.MaxFlashRate), delta/l.MaxBrightnessChange)
+	return uint16(math.Ceil(seconds * 10))
+}
+
+// luminance approximates the relative brightness of a color, from 0 to 1.
+func luminance(red, green, blue uint8) float64 {
+	return (0.2126*float64(red) + 0.7152*float64(green) + 0.0722*float64(blue)) / 255
+}
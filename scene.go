@@ -0,0 +1,270 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// Scene is a set of device snapshots, keyed by configured device name. The
+// default device has an empty name.
+type Scene map[string]Snapshot
+
+// scenesPath returns the path of the saved scenes.
+func scenesPath() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "scenes.json"), nil
+}
+
+// LoadScenes returns the saved scenes by name.
+func LoadScenes() (map[string]Scene, error) {
+	path, err := scenesPath()
+	if err != nil {
+		return nil, err
+	}
+
+	scenes := map[string]Scene{}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return scenes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &scenes)
+	return scenes, err
+}
+
+// SaveScenes replaces the saved scenes.
+func SaveScenes(scenes map[string]Scene) error {
+	path, err := scenesPath()
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(scenes, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// configuredDevices returns the names of the devices in the config file,
+// with an empty name for a device configured at the top level.
+func configuredDevices(cfg *ini.File) []string {
+	var names []string
+	if cfg.Section("").HasKey("host") {
+		names = append(names, "")
+	}
+	for _, section := range cfg.Sections() {
+		if strings.HasPrefix(section.Name(), deviceSectionPrefix) {
+			names = append(names, strings.TrimPrefix(section.Name(), deviceSectionPrefix))
+		}
+	}
+	return names
+}
+
+func doSceneCommand(cfg *ini.File, device string, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf scene save <name> [--all]\n       picoleaf scene apply [--fade <duration>] <name>\n       picoleaf scene delete <name>\n       picoleaf scene list")
+	}
+
+	if len(args) < 1 {
+		return usage()
+	}
+
+	switch args[0] {
+	case "save":
+		fs := flag.NewFlagSet("scene save", flag.ContinueOnError)
+		fs.Usage = func() { fmt.Println(usage()) }
+		all := fs.Bool("all", false, "Capture every configured device")
+		positional, err := parseArgs(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		if len(positional) != 1 {
+			return usage()
+		}
+
+		devices := []string{device}
+		if *all {
+			devices = configuredDevices(cfg)
+		}
+		return doSceneSaveCommand(cfg, positional[0], devices)
+	case "apply":
+		var fade Client
+		fs := newStateFlagSet(&fade, "scene apply")
+		fs.Usage = func() { fmt.Println(usage()) }
+		positional, err := parseArgs(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		if len(positional) != 1 {
+			return usage()
+		}
+		return doSceneApplyCommand(cfg, positional[0], fade.Duration)
+	case "delete":
+		if len(args) != 2 {
+			return usage()
+		}
+		return doSceneDeleteCommand(args[1])
+	case "list":
+		if len(args) != 1 {
+			return usage()
+		}
+		return doSceneListCommand()
+	default:
+		return usage()
+	}
+}
+
+func loadScenes() (map[string]Scene, error) {
+	scenes, err := LoadScenes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenes: %w", err)
+	}
+	return scenes, nil
+}
+
+func saveScenes(scenes map[string]Scene) error {
+	err := SaveScenes(scenes)
+	if err != nil {
+		return fmt.Errorf("failed to save scenes: %w", err)
+	}
+	return nil
+}
+
+// deviceClient returns a client for the named configured device.
+func deviceClient(cfg *ini.File, name string) (Client, error) {
+	section, err := deviceSection(cfg, name)
+	if err != nil {
+		return Client{}, validationErrorf("unknown device: %s", name)
+	}
+	return newClient(section), nil
+}
+
+func doSceneSaveCommand(cfg *ini.File, name string, devices []string) error {
+	scene := Scene{}
+	for _, device := range devices {
+		client, err := deviceClient(cfg, device)
+		if err != nil {
+			return err
+		}
+		snapshot, err := client.Snapshot()
+		if err != nil {
+			return fmt.Errorf("failed to get Nanoleaf state: %w", err)
+		}
+		scene[device] = *snapshot
+	}
+
+	scenes, err := loadScenes()
+	if err != nil {
+		return err
+	}
+	scenes[name] = scene
+	return saveScenes(scenes)
+}
+
+func doSceneApplyCommand(cfg *ini.File, name string, fade time.Duration) error {
+	scenes, err := loadScenes()
+	if err != nil {
+		return err
+	}
+	scene, ok := scenes[name]
+	if !ok {
+		return fmt.Errorf("unknown scene: %v", name)
+	}
+
+	err = applyScene(cfg, scene, fade)
+	if err != nil {
+		return fmt.Errorf("failed to apply scene: %w", err)
+	}
+	return nil
+}
+
+// applyScene applies each device's snapshot in a scene, transitioning over
+// fade. If a device in the scene is no longer configured, nothing is
+// changed.
+func applyScene(cfg *ini.File, scene Scene, fade time.Duration) error {
+	// Check every device is still configured before changing any of them.
+	clients := map[string]Client{}
+	for device := range scene {
+		client, err := deviceClient(cfg, device)
+		if err != nil {
+			return err
+		}
+		client.Duration = fade
+		clients[device] = client
+	}
+
+	for device, snapshot := range scene {
+		err := clients[device].ApplySnapshot(snapshot)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func doSceneDeleteCommand(name string) error {
+	scenes, err := loadScenes()
+	if err != nil {
+		return err
+	}
+	if _, ok := scenes[name]; !ok {
+		return fmt.Errorf("unknown scene: %v", name)
+	}
+	delete(scenes, name)
+	return saveScenes(scenes)
+}
+
+func doSceneListCommand() error {
+	scenes, err := loadScenes()
+	if err != nil {
+		return err
+	}
+	if ok, err := printFormatted(scenes); ok || err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(scenes))
+	for name := range scenes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		scene := scenes[name]
+		if _, ok := scene[""]; ok && len(scene) == 1 {
+			fmt.Println(name)
+			continue
+		}
+
+		var devices []string
+		for device := range scene {
+			if device == "" {
+				device = "default"
+			}
+			devices = append(devices, device)
+		}
+		sort.Strings(devices)
+		fmt.Printf("%s (%s)\n", name, strings.Join(devices, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScheduledJob is a picoleaf command run on a cron schedule.
+type ScheduledJob struct {
+	ID     int      `json:"id"`
+	Spec   string   `json:"spec"`
+	Device string   `json:"device,omitempty"`
+	Args   []string `json:"args"`
+}
+
+// schedulePath returns the path of the saved schedule.
+func schedulePath() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "schedule.json"), nil
+}
+
+// LoadSchedule returns the saved scheduled jobs.
+func LoadSchedule() ([]ScheduledJob, error) {
+	path, err := schedulePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []ScheduledJob
+	err = json.Unmarshal(data, &jobs)
+	return jobs, err
+}
+
+// SaveSchedule replaces the saved scheduled jobs.
+func SaveSchedule(jobs []ScheduledJob) error {
+	path, err := schedulePath()
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+func doScheduleCommand(device string, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf schedule add <cron spec> <command> [args...]\n       picoleaf schedule list\n       picoleaf schedule remove <id>\n       picoleaf schedule run")
+	}
+
+	if len(args) < 1 {
+		return usage()
+	}
+
+	switch args[0] {
+	case "add":
+		// Arguments after the spec belong to the scheduled command, so they
+		// aren't parsed as flags here.
+		if len(args) < 3 {
+			return usage()
+		}
+		return doScheduleAddCommand(device, args[1], args[2:])
+	case "list":
+		if len(args) != 1 {
+			return usage()
+		}
+		return doScheduleListCommand()
+	case "remove":
+		if len(args) != 2 {
+			return usage()
+		}
+		return doScheduleRemoveCommand(args[1])
+	case "run":
+		if len(args) != 1 {
+			return usage()
+		}
+		return doScheduleRunCommand()
+	default:
+		return usage()
+	}
+}
+
+func loadSchedule() ([]ScheduledJob, error) {
+	jobs, err := LoadSchedule()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedule: %w", err)
+	}
+	return jobs, nil
+}
+
+func saveSchedule(jobs []ScheduledJob) error {
+	err := SaveSchedule(jobs)
+	if err != nil {
+		return fmt.Errorf("failed to save schedule: %w", err)
+	}
+	return nil
+}
+
+func doScheduleAddCommand(device, spec string, args []string) error {
+	_, err := parseCron(spec)
+	if err != nil {
+		return fmt.Errorf("invalid schedule: %w", err)
+	}
+
+	jobs, err := loadSchedule()
+	if err != nil {
+		return err
+	}
+	id := 1
+	for _, job := range jobs {
+		if job.ID >= id {
+			id = job.ID + 1
+		}
+	}
+	jobs = append(jobs, ScheduledJob{ID: id, Spec: spec, Device: device, Args: args})
+	if err := saveSchedule(jobs); err != nil {
+		return err
+	}
+	fmt.Println("Added job", id)
+	return nil
+}
+
+func doScheduleListCommand() error {
+	jobs, err := loadSchedule()
+	if err != nil {
+		return err
+	}
+	if ok, err := printFormatted(jobs); ok || err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		device := ""
+		if job.Device != "" {
+			device = fmt.Sprintf(" (%s)", job.Device)
+		}
+		fmt.Printf("%3d  %-16s %s%s\n", job.ID, job.Spec, strings.Join(job.Args, " "), device)
+	}
+	return nil
+}
+
+func doScheduleRemoveCommand(arg string) error {
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		return fmt.Errorf("expected a job ID, got %v", arg)
+	}
+
+	jobs, err := loadSchedule()
+	if err != nil {
+		return err
+	}
+	for i, job := range jobs {
+		if job.ID == id {
+			return saveSchedule(append(jobs[:i], jobs[i+1:]...))
+		}
+	}
+	return fmt.Errorf("no such job: %v", id)
+}
+
+// doScheduleRunCommand runs due jobs until interrupted. The schedule is
+// reread every minute, so added and removed jobs take effect without a
+// restart.
+func doScheduleRunCommand() error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find picoleaf executable: %w", err)
+	}
+
+	for {
+		now := time.Now()
+		time.Sleep(now.Truncate(time.Minute).Add(time.Minute).Sub(now))
+		now = time.Now()
+
+		jobs, err := LoadSchedule()
+		if err != nil {
+			fmt.Println("error: failed to read schedule:", err)
+			continue
+		}
+
+		for _, job := range jobs {
+			schedule, err := parseCron(job.Spec)
+			if err != nil {
+				fmt.Printf("error: job %d: invalid schedule: %v\n", job.ID, err)
+				continue
+			}
+			if schedule.Matches(now) {
+				go runScheduledJob(executable, job)
+			}
+		}
+	}
+}
+
+// runScheduledJob runs a job's command in a separate picoleaf process, so a
+// failing command can't stop the scheduler.
+func runScheduledJob(executable string, job ScheduledJob) {
+	var args []string
+	if job.Device != "" {
+		args = append(args, "-device", job.Device)
+	}
+	args = append(args, job.Args...)
+
+	output, err := exec.Command(executable, args...).CombinedOutput()
+	if err != nil {
+		fmt.Printf("error: job %d failed: %v\n%s", job.ID, err, output)
+		return
+	}
+	fmt.Printf("%s job %d: %s\n", time.Now().Format("2006-01-02 15:04"), job.ID, strings.Join(job.Args, " "))
+	os.Stdout.Write(output)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+func doRunCommand(client Client, cfg *ini.File, section *ini.Section, configFilePath string, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf run [--continue] <file|->")
+	}
+
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	keepGoing := fs.Bool("continue", false, "Keep going after a command fails")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usage()
+	}
+
+	var r io.Reader = os.Stdin
+	if positional[0] != "-" {
+		f, err := os.Open(positional[0])
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	failed := 0
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		command, err := splitCommandLine(scanner.Text())
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(command) == 0 || strings.HasPrefix(command[0], "#") {
+			continue
+		}
+		if client.Verbose {
+			fmt.Println(">", strings.Join(command, " "))
+		}
+
+		// Pausing is `wait`, since `sleep` is the command that fades to off.
+		if command[0] == "wait" {
+			if len(command) != 2 {
+				return fmt.Errorf("line %d: usage: wait <duration>", line)
+			}
+			d, err := time.ParseDuration(command[1])
+			if err != nil || d < 0 {
+				return fmt.Errorf("line %d: expected a duration like 500ms or 2s, got %s", line, command[1])
+			}
+			time.Sleep(d)
+			continue
+		}
+
+		// Every command runs in this process, with the config already
+		// loaded, so scripts don't pay for starting picoleaf on each line.
+		err = runCommand(client, cfg, section, configFilePath, command)
+		if err != nil && reportQueued(err) {
+			err = nil
+		}
+		if err != nil && !*keepGoing {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if err != nil {
+			printError(err, fmt.Sprintf("line %d: ", line))
+			failed++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d command(s) failed", failed)
+	}
+	return nil
+}
+
+// splitCommandLine splits a line into arguments at spaces, like a shell.
+// Single and double quotes group words, and a backslash escapes the next
+// character outside single quotes.
+func splitCommandLine(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != '\'' && r == '\\':
+			if i+1 == len(runes) {
+				return nil, fmt.Errorf("trailing backslash")
+			}
+			i++
+			current.WriteRune(runes[i])
+			inArg = true
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
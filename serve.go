@@ -0,0 +1,374 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// DeviceStatus is the state of a device as reported by `picoleaf serve`.
+// Its fields are part of the served API, so they shouldn't change.
+type DeviceStatus struct {
+	Name             string `json:"name"`
+	Model            string `json:"model"`
+	On               bool   `json:"on"`
+	Brightness       int    `json:"brightness"`
+	ColorMode        string `json:"colorMode"`
+	Hue              int    `json:"hue"`
+	Saturation       int    `json:"sat"`
+	ColorTemperature int    `json:"ct"`
+	Effect           string `json:"effect"`
+}
+
+// server serves a REST API controlling the configured devices.
+type server struct {
+	cfg     *ini.File
+	token   string
+	devices map[string]Client
+	hub     eventHub
+}
+
+// serveDefaultDevice is the name used in URLs for the device configured at
+// the top level of the config file.
+const serveDefaultDevice = "default"
+
+// serveDefaultListen is the address served on by default.
+const serveDefaultListen = ":7800"
+
+// isLoopbackAddress reports whether a listen address only accepts
+// connections from this computer.
+func isLoopbackAddress(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func doServeCommand(cfg *ini.File, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	listen := fs.String("listen", "", "Address to listen on (default :7800, or 127.0.0.1:7800 without a token)")
+	grpcListen := fs.String("grpc", "", "Address to also serve the gRPC API on")
+	token := fs.String("token", cfg.Section("serve").Key("token").String(), "Token callers must send, required unless only listening on loopback")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return usageError("usage: picoleaf serve [--listen <address>] [--grpc <address>] [--token <token>]")
+	}
+
+	// Without a token, anyone who can connect controls the devices, so only
+	// local callers may.
+	if *listen == "" {
+		*listen = serveDefaultListen
+		if *token == "" {
+			*listen = "127.0.0.1" + serveDefaultListen
+		}
+	}
+	for _, address := range []string{*listen, *grpcListen} {
+		if address != "" && *token == "" && !isLoopbackAddress(address) {
+			return fmt.Errorf("a token is required to serve on %s, set --token or [serve] token", address)
+		}
+	}
+
+	s := &server{cfg: cfg, token: *token, devices: map[string]Client{}}
+	for _, name := range configuredDevices(cfg) {
+		section, err := deviceSection(cfg, name)
+		if err != nil {
+			continue
+		}
+		if name == "" {
+			name = serveDefaultDevice
+		}
+		s.devices[name] = newClient(section)
+	}
+	if len(s.devices) == 0 {
+		return errors.New("no devices configured, run `picoleaf pair` first")
+	}
+
+	// Serving stops when either listener fails.
+	errs := make(chan error, 2)
+	if *grpcListen != "" {
+		go func() {
+			err := s.serveGRPC(*grpcListen)
+			errs <- fmt.Errorf("failed to serve gRPC: %w", err)
+		}()
+	}
+	go func() {
+		err := http.ListenAndServe(*listen, traceHandler(s))
+		errs <- fmt.Errorf("failed to serve: %w", err)
+	}()
+	return <-errs
+}
+
+// ServeHTTP routes a request:
+//
+//	GET  /devices                    names of the configured devices
+//	GET  /devices/<name>             the device's DeviceStatus
+//	POST /devices/<name>/on          turn on
+//	POST /devices/<name>/off         turn off
+//	POST /devices/<name>/toggle      turn on or off
+//	PUT  /devices/<name>/brightness  {"value": 0-100}
+//	PUT  /devices/<name>/color       {"value": "<name or #hex>"}
+//	GET  /devices/<name>/effects     the selected effect and all effects
+//	PUT  /devices/<name>/effect      {"value": "<effect name>"}
+//	GET  /scenes                     names of the saved scenes
+//	POST /scenes/<name>              apply a scene
+//	GET  /ws                         a WebSocket pushing events and taking
+//	                                 control messages
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.token != "" && !webhookAuthorized(r, s.token) {
+		writeServeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+	if err := s.checkOrigin(r); err != nil {
+		writeServeError(w, http.StatusForbidden, err)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "devices":
+		s.serveDevices(w, r)
+	case len(parts) >= 2 && parts[0] == "devices":
+		client, ok := s.devices[parts[1]]
+		if !ok {
+			writeServeError(w, http.StatusNotFound, fmt.Errorf("unknown device: %s", parts[1]))
+			return
+		}
+		client.Context = r.Context()
+		action := ""
+		if len(parts) == 3 {
+			action = parts[2]
+		} else if len(parts) > 3 {
+			writeServeError(w, http.StatusNotFound, errors.New("not found"))
+			return
+		}
+		s.serveDevice(w, r, parts[1], client, action)
+	case len(parts) == 1 && parts[0] == "scenes":
+		s.serveScenes(w, r)
+	case len(parts) == 2 && parts[0] == "scenes":
+		s.serveScene(w, r, parts[1])
+	case len(parts) == 1 && parts[0] == "ws":
+		s.serveWebSocket(w, r)
+	default:
+		writeServeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+// checkOrigin accepts requests from any origin when the server has a token,
+// since only callers who know it are let in. Without one, browsers may only
+// send requests from pages served by the same host, so a web page can't
+// drive the devices through a browser on this computer, even with a plain
+// form post.
+func (s *server) checkOrigin(r *http.Request) error {
+	if s.token != "" {
+		return nil
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		// Not a browser.
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host != r.Host {
+		return fmt.Errorf("origin not allowed: %s", origin)
+	}
+	return nil
+}
+
+func (s *server) serveDevices(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	names := []string{}
+	for name := range s.devices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	writeServeJSON(w, names)
+}
+
+func (s *server) serveDevice(w http.ResponseWriter, r *http.Request, name string, client Client, action string) {
+	var err error
+	switch action {
+	case "":
+		if !allowMethod(w, r, http.MethodGet) {
+			return
+		}
+		panelInfo, err := client.GetPanelInfo()
+		if err != nil {
+			writeServeError(w, http.StatusBadGateway, err)
+			return
+		}
+		writeServeJSON(w, DeviceStatus{
+			Name:             name,
+			Model:            panelInfo.Model,
+			On:               panelInfo.State.On.Value,
+			Brightness:       panelInfo.State.Brightness.Value,
+			ColorMode:        panelInfo.State.ColorMode,
+			Hue:              panelInfo.State.Hue.Value,
+			Saturation:       panelInfo.State.Saturation.Value,
+			ColorTemperature: panelInfo.State.ColorTemperature.Value,
+			Effect:           panelInfo.Effects.Selected,
+		})
+		return
+	case "on", "off", "toggle":
+		if !allowMethod(w, r, http.MethodPost) {
+			return
+		}
+		on := action == "on"
+		if action == "toggle" {
+			var panelInfo *PanelInfo
+			panelInfo, err = client.GetPanelInfo()
+			on = err == nil && !panelInfo.State.On.Value
+		}
+		if err == nil && on {
+			err = client.On()
+		} else if err == nil {
+			err = client.Off()
+		}
+	case "brightness":
+		var body struct{ Value int }
+		if !allowMethod(w, r, http.MethodPut) || !readServeBody(w, r, &body) {
+			return
+		}
+		if body.Value < 0 || body.Value > 100 {
+			writeServeError(w, http.StatusBadRequest, errors.New("brightness must be 0-100"))
+			return
+		}
+		err = client.SetBrightness(body.Value)
+	case "color":
+		var body struct{ Value string }
+		if !allowMethod(w, r, http.MethodPut) || !readServeBody(w, r, &body) {
+			return
+		}
+		c, parseErr := parseColor(body.Value, s.cfg.Section("colors"))
+		if parseErr != nil {
+			writeServeError(w, http.StatusBadRequest, parseErr)
+			return
+		}
+		err = client.SetRGB(int(c.Red), int(c.Green), int(c.Blue))
+	case "effects":
+		if !allowMethod(w, r, http.MethodGet) {
+			return
+		}
+		panelInfo, err := client.GetPanelInfo()
+		if err != nil {
+			writeServeError(w, http.StatusBadGateway, err)
+			return
+		}
+		writeServeJSON(w, struct {
+			Selected string   `json:"selected"`
+			Effects  []string `json:"effects"`
+		}{panelInfo.Effects.Selected, panelInfo.Effects.List})
+		return
+	case "effect":
+		var body struct{ Value string }
+		if !allowMethod(w, r, http.MethodPut) || !readServeBody(w, r, &body) {
+			return
+		}
+		err = client.SelectEffect(body.Value)
+	default:
+		writeServeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+
+	if err != nil {
+		writeServeError(w, http.StatusBadGateway, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) serveScenes(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	scenes, err := LoadScenes()
+	if err != nil {
+		writeServeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	names := []string{}
+	for name := range scenes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	writeServeJSON(w, names)
+}
+
+func (s *server) serveScene(w http.ResponseWriter, r *http.Request, name string) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	scenes, err := LoadScenes()
+	if err != nil {
+		writeServeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	scene, ok := scenes[name]
+	if !ok {
+		writeServeError(w, http.StatusNotFound, fmt.Errorf("unknown scene: %s", name))
+		return
+	}
+	err = applyScene(s.cfg, scene, 0)
+	if errors.Is(err, ErrValidation) {
+		// The scene names a device that's no longer configured.
+		writeServeError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		writeServeError(w, http.StatusBadGateway, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// allowMethod reports whether r uses method, responding with an error if
+// not.
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		writeServeError(w, http.StatusMethodNotAllowed, fmt.Errorf("expected %s", method))
+		return false
+	}
+	return true
+}
+
+// readServeBody decodes a JSON request body into v, responding with an
+// error if it's invalid.
+func readServeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err != nil {
+		writeServeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+func writeServeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeServeError responds with status and the error as JSON, without
+// details that would reveal access tokens.
+func writeServeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": publicError(err)})
+}
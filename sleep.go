@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func doSleepCommand(client Client, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf sleep <duration>")
+	}
+
+	fs := flag.NewFlagSet("sleep", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return usage()
+	}
+	duration, err := time.ParseDuration(positional[0])
+	if err != nil || duration <= 0 {
+		return fmt.Errorf("expected a duration like 30m, got %v", positional[0])
+	}
+
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	state := panelInfo.State
+	if !state.On.Value {
+		return nil
+	}
+	original := state.Brightness.Value
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	// Dim one step at a time, so the fade can be interrupted and restored.
+	// The last step turns the panels off, with their original brightness so
+	// they come back at it.
+	client.Duration = 0
+	step := duration / time.Duration(original+1)
+	for brightness := original - 1; brightness >= -1; brightness-- {
+		select {
+		case <-time.After(step):
+		case <-signals:
+			err := client.SetBrightness(original)
+			if err != nil {
+				return fmt.Errorf("failed to restore brightness: %w", err)
+			}
+			return nil
+		}
+
+		if brightness < 0 {
+			err = client.putState(State{
+				On:         &OnProperty{false},
+				Brightness: &BrightnessProperty{Value: original},
+			})
+		} else {
+			err = client.SetBrightness(brightness)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to dim Nanoleaf: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+// Snapshot is a saved Nanoleaf state that can be reapplied later.
+type Snapshot struct {
+	On               bool    `json:"on"`
+	Brightness       int     `json:"brightness"`
+	ColorMode        string  `json:"colorMode"`
+	Hue              int     `json:"hue,omitempty"`
+	Saturation       int     `json:"sat,omitempty"`
+	ColorTemperature int     `json:"ct,omitempty"`
+	Effect           string  `json:"effect,omitempty"`
+	EffectDefinition *Effect `json:"effectDefinition,omitempty"`
+}
+
+// Snapshot captures the Nanoleaf's current state. Effects that aren't in
+// the effects list, like ones displayed by picoleaf, are saved with their
+// definition when the device will provide it.
+func (c Client) Snapshot() (*Snapshot, error) {
+	panelInfo, err := c.GetPanelInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	state := panelInfo.State
+	snapshot := Snapshot{
+		On:         state.On.Value,
+		Brightness: state.Brightness.Value,
+		ColorMode:  state.ColorMode,
+	}
+	switch state.ColorMode {
+	case "hs":
+		snapshot.Hue = state.Hue.Value
+		snapshot.Saturation = state.Saturation.Value
+	case "ct":
+		snapshot.ColorTemperature = state.ColorTemperature.Value
+	default:
+		snapshot.Effect = panelInfo.Effects.Selected
+		listed := false
+		for _, name := range panelInfo.Effects.List {
+			listed = listed || name == snapshot.Effect
+		}
+		if !listed {
+			effect, err := c.RequestEffect(snapshot.Effect)
+			if err == nil && effect.Validate() == nil {
+				snapshot.EffectDefinition = effect
+			}
+		}
+	}
+	return &snapshot, nil
+}
+
+// ApplySnapshot restores a saved state. A snapshot of the Nanoleaf turned
+// off only restores its brightness, since setting a color would turn it on.
+func (c Client) ApplySnapshot(s Snapshot) error {
+	if !s.On {
+		return c.putState(State{
+			On:         &OnProperty{false},
+			Brightness: &BrightnessProperty{Value: s.Brightness},
+		})
+	}
+
+	state := State{
+		On:         &OnProperty{true},
+		Brightness: &BrightnessProperty{Value: s.Brightness},
+	}
+	switch s.ColorMode {
+	case "hs":
+		state.Hue = &HueProperty{Value: s.Hue}
+		state.Saturation = &SaturationProperty{Value: s.Saturation}
+	case "ct":
+		state.ColorTemperature = &ColorTemperatureProperty{Value: s.ColorTemperature}
+	default:
+		var err error
+		if s.EffectDefinition != nil {
+			err = c.DisplayEffect(*s.EffectDefinition)
+		} else {
+			err = c.SelectEffect(s.Effect)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return c.SetState(state)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"net"
+)
+
+// Stream sends panel colors to Nanoleaf in real time over the external
+// control protocol. Colors set with SetPanel are sent together by Flush.
+type Stream struct {
+	conn    *net.UDPConn
+	version string
+	limits  SafetyLimits
+	pending []SetPanelColor
+	last    map[uint16]SetPanelColor
+}
+
+// OpenStream puts Nanoleaf into external control mode and returns a Stream
+// to it. The protocol version is negotiated from the panel model unless
+// the client's StreamVersion is set.
+func (c Client) OpenStream() (*Stream, error) {
+	version, err := c.streamVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	raddr, err := c.startExternalControl(version)
+	if err != nil {
+		return nil, err
+	}
+
+	laddr, err := net.ResolveUDPAddr("udp", ":0")
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialUDP("udp", laddr, raddr)
+	if err != nil {
+		return nil, &NetworkError{Op: "stream", Err: err}
+	}
+
+	s := &Stream{
+		conn:    conn,
+		version: version,
+		limits:  c.safetyLimits(),
+		last:    make(map[uint16]SetPanelColor),
+	}
+	return s, nil
+}
+
+// Version returns the external control protocol version of the stream.
+func (s *Stream) Version() string {
+	return s.version
+}
+
+// SetPanel queues a color for a panel, to be reached over the transition
+// time in tenths of a second. It is sent by the next Flush.
+func (s *Stream) SetPanel(id uint16, red, green, blue uint8, transition uint16) {
+	s.pending = append(s.pending, SetPanelColor{
+		PanelID:        id,
+		Red:            red,
+		Green:          green,
+		Blue:           blue,
+		TransitionTime: transition,
+	})
+}
+
+// Flush sends the queued panel colors. Transitions are lengthened where
+// needed to respect the client's safety limits, taking into account the
+// colors sent by earlier flushes.
+func (s *Stream) Flush() error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	var previous []SetPanelColor
+	for _, frame := range s.pending {
+		if prev, ok := s.last[frame.PanelID]; ok {
+			previous = append(previous, prev)
+			delete(s.last, frame.PanelID)
+		}
+	}
+	frames := s.limits.LimitFrames(append(previous, s.pending...))[len(previous):]
+	s.pending = nil
+
+	for _, frame := range frames {
+		s.last[frame.PanelID] = frame
+	}
+
+	buf, err := encodeFrames(s.version, frames)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.conn.Write(buf)
+	if err != nil {
+		return &NetworkError{Op: "stream", Err: err}
+	}
+	return nil
+}
+
+// Close closes the stream. Nanoleaf stays in external control mode until
+// another effect is selected.
+func (s *Stream) Close() error {
+	return s.conn.Close()
+}
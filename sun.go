@@ -0,0 +1,31 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// sunElevation returns the sun's angle above the horizon in degrees at a
+// time and place, using NOAA's approximate solar position equations.
+func sunElevation(t time.Time, latitude, longitude float64) float64 {
+	t = t.UTC()
+	hours := float64(t.Hour()) + float64(t.Minute())/60 + float64(t.Second())/3600
+
+	// Fractional year, in radians.
+	g := 2 * math.Pi / 365 * (float64(t.YearDay()-1) + (hours-12)/24)
+
+	// Equation of time, in minutes, and solar declination, in radians.
+	eqtime := 229.18 * (0.000075 + 0.001868*math.Cos(g) - 0.032077*math.Sin(g) -
+		0.014615*math.Cos(2*g) - 0.040849*math.Sin(2*g))
+	decl := 0.006918 - 0.399912*math.Cos(g) + 0.070257*math.Sin(g) -
+		0.006758*math.Cos(2*g) + 0.000907*math.Sin(2*g) -
+		0.002697*math.Cos(3*g) + 0.00148*math.Sin(3*g)
+
+	solarMinutes := hours*60 + eqtime + 4*longitude
+	hourAngle := (solarMinutes/4 - 180) * math.Pi / 180
+
+	lat := latitude * math.Pi / 180
+	cosZenith := math.Sin(lat)*math.Sin(decl) + math.Cos(lat)*math.Cos(decl)*math.Cos(hourAngle)
+	cosZenith = math.Max(-1, math.Min(1, cosZenith))
+	return 90 - math.Acos(cosZenith)*180/math.Pi
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// textFPS is how many frames a second scrolling text is streamed at.
+const textFPS = 20
+
+// gridCell is a panel's position in a grid fitted to the layout, with row 0
+// at the top.
+type gridCell struct {
+	Col int
+	Row int
+}
+
+// layoutGrid fits a grid to the panels, with cells as large as the closest
+// pair of panels, and returns each panel's cell and the number of rows and
+// columns.
+func layoutGrid(polygons map[int][]point) (map[uint16]gridCell, int, int) {
+	centers := make(map[int]point, len(polygons))
+	min := point{math.Inf(1), math.Inf(1)}
+	max := point{math.Inf(-1), math.Inf(-1)}
+	for id, polygon := range polygons {
+		c := centroid(polygon)
+		centers[id] = c
+		min.X, min.Y = math.Min(min.X, c.X), math.Min(min.Y, c.Y)
+		max.X, max.Y = math.Max(max.X, c.X), math.Max(max.Y, c.Y)
+	}
+
+	cell := math.Inf(1)
+	for a, p := range centers {
+		for b, q := range centers {
+			if a != b {
+				cell = math.Min(cell, math.Hypot(p.X-q.X, p.Y-q.Y))
+			}
+		}
+	}
+	if math.IsInf(cell, 1) || cell == 0 {
+		cell = 1
+	}
+
+	cells := make(map[uint16]gridCell, len(centers))
+	cols, rows := 0, 0
+	for id, c := range centers {
+		g := gridCell{
+			Col: int(math.Round((c.X - min.X) / cell)),
+			Row: int(math.Round((max.Y - c.Y) / cell)),
+		}
+		cells[uint16(id)] = g
+		if g.Col+1 > cols {
+			cols = g.Col + 1
+		}
+		if g.Row+1 > rows {
+			rows = g.Row + 1
+		}
+	}
+	return cells, cols, rows
+}
+
+func doTextCommand(client Client, colors *ini.Section, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf text <text> [--color <color>] [--background <color>] [--speed <columns per second>] [--once]")
+	}
+
+	fs := flag.NewFlagSet("text", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	colorName := fs.String("color", "white", "Text color")
+	backgroundName := fs.String("background", "black", "Background color")
+	speed := fs.Float64("speed", 3, "Scrolling speed in panels per second")
+	once := fs.Bool("once", false, "Scroll the text once instead of until interrupted")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 || *speed <= 0 {
+		return usage()
+	}
+
+	fg, err := parseColor(*colorName, colors)
+	if err != nil {
+		return err
+	}
+	bg, err := parseColor(*backgroundName, colors)
+	if err != nil {
+		return err
+	}
+	fg, bg = client.calibrated(fg), client.calibrated(bg)
+
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	polygons := layoutPolygons(panelInfo)
+	if len(polygons) == 0 {
+		return errors.New("no panels in layout")
+	}
+
+	cells, cols, rows := layoutGrid(polygons)
+	bitmap := renderText(positional[0])
+	width := len(bitmap[0])
+	top := (rows - fontHeight) / 2
+
+	// The text starts just off the right edge and scrolls until it has
+	// left the left edge.
+	span := float64(cols + width)
+	interval := time.Second / textFPS
+	frame := 0
+	return streamFrames(client, func() ([]SetPanelColor, time.Duration, bool, error) {
+		scrolled := float64(frame) * interval.Seconds() * *speed
+		if *once && scrolled > span {
+			return nil, 0, false, nil
+		}
+		frame++
+		offset := int(math.Mod(scrolled, span)) - cols
+
+		colors := make(map[uint16]RGB, len(cells))
+		for id, cell := range cells {
+			x, y := cell.Col+offset, cell.Row-top
+			lit := x >= 0 && x < width && y >= 0 && y < fontHeight && bitmap[y][x]
+			colors[id] = bg
+			if lit {
+				colors[id] = fg
+			}
+		}
+		return panelColorFrame(colors, 0), interval, true, nil
+	})
+}
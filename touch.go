@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+func doTouchDaemonCommand(client Client, cfg *ini.File, args []string) error {
+	if len(args) != 0 {
+		return usageError("usage: picoleaf touch-daemon")
+	}
+
+	commands := cfg.Section("touch")
+	gestures := cfg.Section("gestures")
+	if len(commands.Keys()) == 0 && len(gestures.Keys()) == 0 {
+		return errors.New("no gestures configured in the [touch] or [gestures] sections")
+	}
+	actions, err := parseGestureActions(cfg)
+	if err != nil {
+		return fmt.Errorf("invalid gesture action: %w", err)
+	}
+
+	for {
+		err := client.SubscribeEvents([]EventType{EventTouch}, func(event Event) error {
+			if event.Type != EventTouch {
+				return nil
+			}
+			for _, item := range event.Items {
+				if key := touchBindingKey(commands, item); key != "" {
+					go runTouchCommand(commands.Key(key).String(), item)
+				}
+				if key := touchBindingKey(gestures, item); key != "" {
+					fmt.Printf("%s %s on panel %d: %s\n", time.Now().Format("2006-01-02 15:04:05"), item.Gesture, item.PanelID, gestures.Key(key).String())
+					ctx, span := startSpan(nil, "gesture "+key)
+					client := client
+					client.Context = ctx
+					err := actions[key](client)
+					endSpan(span, err)
+					if err != nil {
+						fmt.Println("error: failed to run gesture action:", err)
+					}
+				}
+			}
+			return nil
+		})
+		if !errors.Is(err, ErrNetwork) {
+			return fmt.Errorf("failed to listen for Nanoleaf events: %w", err)
+		}
+		fmt.Println("error: lost connection to Nanoleaf events:", err)
+		time.Sleep(eventReconnectDelay)
+	}
+}
+
+// touchBindingKey returns the key in section bound to a touch, or "" if
+// there is none. A key for the gesture on the touched panel, like
+// "tap.1234", takes precedence over one for the gesture anywhere, like
+// "tap".
+func touchBindingKey(section *ini.Section, item EventItem) string {
+	gesture := item.Gesture.String()
+	for _, key := range []string{gesture + "." + strconv.Itoa(item.PanelID), gesture} {
+		if section.HasKey(key) {
+			return key
+		}
+	}
+	return ""
+}
+
+// runTouchCommand runs a shell command bound to a touch, passing the gesture
+// and panel in its environment.
+func runTouchCommand(command string, item EventItem) {
+	shell, option := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, option = "cmd", "/C"
+	}
+
+	cmd := exec.Command(shell, option, command)
+	cmd.Env = append(os.Environ(),
+		"PICOLEAF_GESTURE="+item.Gesture.String(),
+		"PICOLEAF_PANEL="+strconv.Itoa(item.PanelID),
+	)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	fmt.Printf("%s %s on panel %d: %s\n", time.Now().Format("2006-01-02 15:04:05"), item.Gesture, item.PanelID, command)
+	err := cmd.Run()
+	if err != nil {
+		fmt.Printf("error: %s failed: %v\n", command, err)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+// TraceEntry records a single API request and its response.
+type TraceEntry struct {
+	Time         time.Time `json:"time"`
+	Method       string    `json:"method"`
+	URL          string    `json:"url"`
+	RequestBody  string    `json:"requestBody,omitempty"`
+	Status       int       `json:"status,omitempty"`
+	ResponseBody string    `json:"responseBody,omitempty"`
+	Duration     float64   `json:"durationMs"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// Trace records API requests to a JSON file. The file is rewritten after
+// every request so the trace is complete even if the program exits early.
+type Trace struct {
+	Path string
+
+	mu      sync.Mutex
+	entries []TraceEntry
+}
+
+// NewTrace returns a Trace that writes to the file at path.
+func NewTrace(path string) *Trace {
+	return &Trace{Path: path, entries: []TraceEntry{}}
+}
+
+// Record adds an entry to the trace and writes it to disk.
+func (t *Trace) Record(entry TraceEntry) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.entries = append(t.entries, entry)
+	data, err := json.MarshalIndent(t.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(t.Path, data, 0644)
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HAREntry records a single API request and its response, in HTTP Archive
+// (HAR) 1.2 format.
+type HAREntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+// HARRequest is the request of a HAREntry.
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []struct{}     `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// HARResponse is the response of a HAREntry. Status is 0 if no response
+// was received.
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []struct{}     `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// HARNameValue is a header or query parameter.
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARPostData is a request body.
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// HARContent is a response body.
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+// HARTimings splits an entry's time, in milliseconds, into waiting for the
+// response headers and receiving the body. Sending isn't measured
+// separately, so it's included in waiting.
+type HARTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// Transcript records API requests and responses, with their headers, to a
+// HAR file that can be opened in browser developer tools and HAR viewers.
+// Like a Trace, the file is rewritten after every request.
+type Transcript struct {
+	Path string
+
+	mu      sync.Mutex
+	entries []HAREntry
+}
+
+// NewTranscript returns a Transcript that writes to the file at path.
+func NewTranscript(path string) *Transcript {
+	return &Transcript{Path: path, entries: []HAREntry{}}
+}
+
+// Record adds an entry to the transcript and writes it to disk.
+func (t *Transcript) Record(entry HAREntry) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.entries = append(t.entries, entry)
+	var har struct {
+		Log struct {
+			Version string `json:"version"`
+			Creator struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"creator"`
+			Entries []HAREntry `json:"entries"`
+		} `json:"log"`
+	}
+	har.Log.Version = "1.2"
+	har.Log.Creator.Name = "picoleaf"
+	har.Log.Entries = t.entries
+	data, err := json.MarshalIndent(har, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(t.Path, data, 0644)
+}
+
+// recordExchange adds a request and its response to the client's
+// transcript, if it has one, with the access token redacted from URLs. res
+// is nil if the request failed, and responseBody is nil if the body wasn't
+// read. headersAt is when the response headers arrived.
+func (c Client) recordExchange(req *http.Request, requestBody []byte, res *http.Response, responseBody []byte, start time.Time, headersAt time.Time, err error) {
+	if c.Transcript == nil {
+		return
+	}
+
+	end := time.Now()
+	if headersAt.IsZero() {
+		headersAt = end
+	}
+	entry := HAREntry{
+		StartedDateTime: start,
+		Time:            milliseconds(end.Sub(start)),
+		Request: HARRequest{
+			Method:      req.Method,
+			URL:         redact(req.URL.String()),
+			HTTPVersion: req.Proto,
+			Cookies:     []struct{}{},
+			Headers:     harHeaders(req.Header),
+			QueryString: []HARNameValue{},
+			HeadersSize: -1,
+			BodySize:    len(requestBody),
+		},
+		Response: HARResponse{
+			Cookies:     []struct{}{},
+			Headers:     []HARNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings: HARTimings{
+			Wait:    milliseconds(headersAt.Sub(start)),
+			Receive: milliseconds(end.Sub(headersAt)),
+		},
+	}
+	for name, values := range req.URL.Query() {
+		for _, value := range values {
+			entry.Request.QueryString = append(entry.Request.QueryString, HARNameValue{name, value})
+		}
+	}
+	if requestBody != nil {
+		entry.Request.PostData = &HARPostData{
+			MimeType: req.Header.Get("Content-Type"),
+			Text:     string(requestBody),
+		}
+	}
+
+	if res != nil {
+		entry.Response.Status = res.StatusCode
+		entry.Response.StatusText = http.StatusText(res.StatusCode)
+		entry.Response.HTTPVersion = res.Proto
+		entry.Response.Headers = harHeaders(res.Header)
+		entry.Response.Content.MimeType = res.Header.Get("Content-Type")
+		if responseBody != nil {
+			entry.Response.BodySize = len(responseBody)
+			entry.Response.Content.Size = len(responseBody)
+			entry.Response.Content.Text = string(responseBody)
+		}
+	}
+	if err != nil {
+		entry.Comment = redact(err.Error())
+	}
+
+	if recordErr := c.Transcript.Record(entry); recordErr != nil {
+		fmt.Fprintln(os.Stderr, "warning: failed to write transcript:", recordErr)
+	}
+}
+
+// harHeaders returns headers in a stable order.
+func harHeaders(header http.Header) []HARNameValue {
+	headers := []HARNameValue{}
+	for name, values := range header {
+		for _, value := range values {
+			headers = append(headers, HARNameValue{name, value})
+		}
+	}
+	sort.SliceStable(headers, func(i, j int) bool {
+		return headers[i].Name < headers[j].Name
+	})
+	return headers
+}
+
+func milliseconds(d time.Duration) float64 {
+	return d.Seconds() * 1000
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+)
+
+// transitionInterval is the time between client-side interpolation steps.
+const transitionInterval = 100 * time.Millisecond
+
+// putState sends a partial state update.
+func (c Client) putState(state State) error {
+	bytes, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	_, err = c.Put("state", bytes)
+	return err
+}
+
+// durationSeconds returns the transition duration in the whole seconds the
+// API expects.
+func (c Client) durationSeconds() int {
+	return int(math.Round(c.Duration.Seconds()))
+}
+
+// interpolate calls step with t moving linearly from 0 to 1 over the
+// transition duration. The final call always has t == 1.
+func (c Client) interpolate(step func(t float64) error) error {
+	steps := int(c.Duration / transitionInterval)
+	if steps < 1 {
+		steps = 1
+	}
+
+	for i := 1; i <= steps; i++ {
+		err := step(float64(i) / float64(steps))
+		if err != nil {
+			return err
+		}
+		if i < steps {
+			time.Sleep(transitionInterval)
+		}
+	}
+	return nil
+}
+
+// fadeOn turns Nanoleaf on at zero brightness, then raises it to its
+// previous brightness.
+func (c Client) fadeOn() error {
+	panelInfo, err := c.GetPanelInfo()
+	if err != nil {
+		return err
+	}
+
+	brightness := panelInfo.State.Brightness.Value
+	if !panelInfo.State.On.Value {
+		err = c.putState(State{
+			On:         &OnProperty{true},
+			Brightness: &BrightnessProperty{Value: 0},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.putState(State{
+		Brightness: &BrightnessProperty{Value: brightness, Duration: c.durationSeconds()},
+	})
+}
+
+// fadeOff dims Nanoleaf to zero brightness, then turns it off. The previous
+// brightness is restored with the power change so the next On is unaffected.
+func (c Client) fadeOff() error {
+	panelInfo, err := c.GetPanelInfo()
+	if err != nil {
+		return err
+	}
+
+	brightness := panelInfo.State.Brightness.Value
+	err = c.putState(State{
+		Brightness: &BrightnessProperty{Value: 0, Duration: c.durationSeconds()},
+	})
+	if err != nil {
+		return err
+	}
+
+	time.Sleep(c.Duration)
+
+	return c.putState(State{
+		On:         &OnProperty{false},
+		Brightness: &BrightnessProperty{Value: brightness},
+	})
+}
+
+// SetState sets the properties given in state, fading to them over the
+// transition duration.
+func (c Client) SetState(state State) error {
+	if c.Duration > 0 {
+		return c.fadeState(state)
+	}
+	return c.putState(state)
+}
+
+// fadeState transitions to the properties given in target together. Power
+// changes immediately and brightness fades on the device. Hue, saturation
+// and color temperature have no transitions in the API, so they're
+// interpolated from their current values.
+func (c Client) fadeState(target State) error {
+	panelInfo, err := c.GetPanelInfo()
+	if err != nil {
+		return err
+	}
+
+	if target.On != nil || target.Brightness != nil {
+		state := State{On: target.On}
+		if target.Brightness != nil {
+			state.Brightness = &BrightnessProperty{Value: target.Brightness.Value, Duration: c.durationSeconds()}
+		}
+		err = c.putState(state)
+		if err != nil {
+			return err
+		}
+	}
+	if target.Hue == nil && target.Saturation == nil && target.ColorTemperature == nil {
+		return nil
+	}
+
+	from := panelInfo.State
+	return c.interpolate(func(t float64) error {
+		var step State
+		if target.Hue != nil {
+			step.Hue = &HueProperty{Value: lerpHue(from.Hue.Value, target.Hue.Value, t)}
+		}
+		if target.Saturation != nil {
+			step.Saturation = &SaturationProperty{Value: lerp(from.Saturation.Value, target.Saturation.Value, t)}
+		}
+		if target.ColorTemperature != nil {
+			step.ColorTemperature = &ColorTemperatureProperty{Value: lerp(from.ColorTemperature.Value, target.ColorTemperature.Value, t)}
+		}
+		return c.putState(step)
+	})
+}
+
+// fadeColorTemperature interpolates from the current color temperature.
+func (c Client) fadeColorTemperature(temperature int) error {
+	return c.fadeState(State{ColorTemperature: &ColorTemperatureProperty{Value: temperature}})
+}
+
+// fadeHue interpolates from the current hue.
+func (c Client) fadeHue(hue int) error {
+	return c.fadeState(State{Hue: &HueProperty{Value: hue}})
+}
+
+// fadeSaturation interpolates from the current saturation.
+func (c Client) fadeSaturation(sat int) error {
+	return c.fadeState(State{Saturation: &SaturationProperty{Value: sat}})
+}
+
+// fadeHSV transitions brightness on the device while interpolating hue and
+// saturation from their current values.
+func (c Client) fadeHSV(hue int, sat int, value int) error {
+	return c.fadeState(State{
+		Brightness: &BrightnessProperty{Value: value},
+		Hue:        &HueProperty{Value: hue},
+		Saturation: &SaturationProperty{Value: sat},
+	})
+}
+
+// lerp linearly interpolates between two integers.
+func lerp(from, to int, t float64) int {
+	return from + int(math.Round(float64(to-from)*t))
+}
+
+// lerpHue interpolates between two hues in degrees along the shorter way
+// around the color wheel.
+func lerpHue(from, to int, t float64) int {
+	diff := to - from
+	if diff > 180 {
+		diff -= 360
+	} else if diff < -180 {
+		diff += 360
+	}
+
+	h := (from + int(math.Round(float64(diff)*t))) % 360
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// tuiRefreshInterval is how often the dashboard reloads the Nanoleaf state.
+const tuiRefreshInterval = 2 * time.Second
+
+// tuiControls are the dashboard's adjustable properties, in focus order,
+// with how far the arrow keys move them.
+var tuiControls = []struct {
+	Name string
+	Step int
+}{
+	{"Brightness", 5},
+	{"Hue", 10},
+	{"Saturation", 5},
+	{"Temperature", 100},
+	{"Effects", 1},
+}
+
+// tui is the state of the terminal dashboard.
+type tui struct {
+	client    Client
+	screen    tcell.Screen
+	panelInfo *PanelInfo
+	focus     int
+	effect    int // Highlighted entry in the effects list
+	message   string
+}
+
+func doTUICommand(client Client, args []string) error {
+	if len(args) != 0 {
+		return usageError("usage: picoleaf tui")
+	}
+
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	screen, err := tcell.NewScreen()
+	if err == nil {
+		err = screen.Init()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to start terminal UI: %w", err)
+	}
+	defer screen.Fini()
+
+	t := &tui{client: client, screen: screen, panelInfo: panelInfo}
+	for i, name := range panelInfo.Effects.List {
+		if name == panelInfo.Effects.Selected {
+			t.effect = i
+		}
+	}
+
+	go func() {
+		for range time.Tick(tuiRefreshInterval) {
+			screen.PostEvent(tcell.NewEventInterrupt(nil))
+		}
+	}()
+
+	for {
+		t.draw()
+		switch ev := screen.PollEvent().(type) {
+		case *tcell.EventResize:
+			screen.Sync()
+		case *tcell.EventInterrupt:
+			t.refresh()
+		case *tcell.EventKey:
+			if !t.handleKey(ev) {
+				return nil
+			}
+		}
+	}
+}
+
+// refresh reloads the Nanoleaf state, keeping the previous state on error.
+func (t *tui) refresh() {
+	panelInfo, err := t.client.GetPanelInfo()
+	if err != nil {
+		t.message = "error: failed to get Nanoleaf state: " + err.Error()
+		return
+	}
+	t.panelInfo = panelInfo
+}
+
+// handleKey acts on a key press, returning false to quit.
+func (t *tui) handleKey(ev *tcell.EventKey) bool {
+	t.message = ""
+	control := tuiControls[t.focus]
+	state := t.panelInfo.State
+	effects := t.panelInfo.Effects.List
+
+	var err error
+	switch {
+	case ev.Key() == tcell.KeyEscape || ev.Key() == tcell.KeyCtrlC || ev.Rune() == 'q':
+		return false
+	case ev.Key() == tcell.KeyTab:
+		t.focus = (t.focus + 1) % len(tuiControls)
+	case ev.Key() == tcell.KeyBacktab:
+		t.focus = (t.focus + len(tuiControls) - 1) % len(tuiControls)
+	case ev.Rune() == ' ':
+		if state.On.Value {
+			err = t.client.Off()
+		} else {
+			err = t.client.On()
+		}
+	case ev.Rune() == 'r':
+		t.refresh()
+	case control.Name == "Effects" && ev.Key() == tcell.KeyUp:
+		if t.effect > 0 {
+			t.effect--
+		}
+	case control.Name == "Effects" && ev.Key() == tcell.KeyDown:
+		if t.effect < len(effects)-1 {
+			t.effect++
+		}
+	case control.Name == "Effects" && ev.Key() == tcell.KeyEnter:
+		if t.effect < len(effects) {
+			err = t.client.SelectEffect(effects[t.effect])
+		}
+	case ev.Key() == tcell.KeyLeft || ev.Key() == tcell.KeyRight:
+		step := control.Step
+		if ev.Key() == tcell.KeyLeft {
+			step = -step
+		}
+		err = t.adjust(control.Name, step)
+	default:
+		return true
+	}
+
+	if err != nil {
+		t.message = "error: " + err.Error()
+	}
+	t.refresh()
+	return true
+}
+
+// adjust changes a property by step, within its range.
+func (t *tui) adjust(name string, step int) error {
+	state := t.panelInfo.State
+	clamp := func(v int, min, max *int) int {
+		if min != nil && v < *min {
+			v = *min
+		}
+		if max != nil && v > *max {
+			v = *max
+		}
+		return v
+	}
+
+	switch name {
+	case "Brightness":
+		return t.client.SetBrightness(clamp(state.Brightness.Value+step, state.Brightness.Min, state.Brightness.Max))
+	case "Hue":
+		return t.client.SetHue((state.Hue.Value + step + 360) % 360)
+	case "Saturation":
+		return t.client.SetSaturation(clamp(state.Saturation.Value+step, state.Saturation.Min, state.Saturation.Max))
+	case "Temperature":
+		ct := state.ColorTemperature
+		return t.client.SetColorTemperature(clamp(ct.Value+step, ct.Min, ct.Max))
+	}
+	return nil
+}
+
+// draw renders the dashboard.
+func (t *tui) draw() {
+	s := t.screen
+	s.Clear()
+	width, height := s.Size()
+	bold := tcell.StyleDefault.Bold(true)
+
+	info := t.panelInfo
+	state := info.State
+	power := "off"
+	if state.On.Value {
+		power = "on"
+	}
+	t.text(0, 0, bold, fmt.Sprintf("%s  (%s)", info.Name, info.Model))
+	t.text(0, 1, tcell.StyleDefault, fmt.Sprintf("Power: %s    Mode: %s    Effect: %s", power, state.ColorMode, info.Effects.Selected))
+
+	intOr := func(p *int, fallback int) int {
+		if p == nil {
+			return fallback
+		}
+		return *p
+	}
+	values := map[string][3]int{
+		"Brightness":  {state.Brightness.Value, intOr(state.Brightness.Min, 0), intOr(state.Brightness.Max, 100)},
+		"Hue":         {state.Hue.Value, intOr(state.Hue.Min, 0), intOr(state.Hue.Max, 360)},
+		"Saturation":  {state.Saturation.Value, intOr(state.Saturation.Min, 0), intOr(state.Saturation.Max, 100)},
+		"Temperature": {state.ColorTemperature.Value, intOr(state.ColorTemperature.Min, 1200), intOr(state.ColorTemperature.Max, 6500)},
+	}
+
+	y := 3
+	for i, control := range tuiControls {
+		style := tcell.StyleDefault
+		if i == t.focus {
+			style = style.Reverse(true)
+		}
+		if control.Name == "Effects" {
+			break
+		}
+		v := values[control.Name]
+		t.text(0, y, style, fmt.Sprintf("%-12s", control.Name))
+		t.text(13, y, tcell.StyleDefault, tuiSlider(v[0], v[1], v[2], 30)+fmt.Sprintf(" %d", v[0]))
+		y++
+	}
+
+	y++
+	effectsStyle := tcell.StyleDefault
+	if tuiControls[t.focus].Name == "Effects" {
+		effectsStyle = effectsStyle.Reverse(true)
+	}
+	t.text(0, y, effectsStyle, "Effects")
+	y++
+
+	// Show a window of the effects list around the highlighted entry,
+	// leaving room for the layout.
+	effects := info.Effects.List
+	rows := 8
+	first := t.effect - rows/2
+	if first > len(effects)-rows {
+		first = len(effects) - rows
+	}
+	if first < 0 {
+		first = 0
+	}
+	for i := first; i < len(effects) && i < first+rows; i++ {
+		marker := "  "
+		if effects[i] == info.Effects.Selected {
+			marker = "* "
+		}
+		style := tcell.StyleDefault
+		if i == t.effect && tuiControls[t.focus].Name == "Effects" {
+			style = style.Reverse(true)
+		}
+		t.text(2, y, style, marker+effects[i])
+		y++
+	}
+
+	y++
+	polygons := layoutPolygons(info)
+	if len(polygons) > 0 && width > 10 {
+		for _, line := range renderLayoutASCII(polygons, width-2) {
+			if y >= height-2 {
+				break
+			}
+			t.text(1, y, tcell.StyleDefault, line)
+			y++
+		}
+	}
+
+	t.text(0, height-2, tcell.StyleDefault, t.message)
+	t.text(0, height-1, bold, "tab: next  ←/→: adjust  ↑/↓/enter: effects  space: power  r: refresh  q: quit")
+	s.Show()
+}
+
+// text draws a line of text on the dashboard.
+func (t *tui) text(x, y int, style tcell.Style, text string) {
+	drawText(t.screen, x, y, style, text)
+}
+
+// drawText draws a line of text, clipped to the screen.
+func drawText(s tcell.Screen, x, y int, style tcell.Style, text string) {
+	width, _ := s.Size()
+	for _, r := range text {
+		if x >= width {
+			return
+		}
+		s.SetContent(x, y, r, nil, style)
+		x++
+	}
+}
+
+// tuiSlider draws a value as a bar of the given width.
+func tuiSlider(value, min, max, width int) string {
+	filled := 0
+	if max > min {
+		filled = (value - min) * width / (max - min)
+	}
+	if filled < 0 {
+		filled = 0
+	}
+	if filled > width {
+		filled = width
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", width-filled) + "]"
+}
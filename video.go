@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// videoFrameSize is the width and height frames are decoded at. It only
+// needs to be detailed enough to average over each panel.
+const videoFrameSize = 64
+
+// VideoDecoder produces the frames of a video.
+type VideoDecoder interface {
+	// Next returns the next frame, or io.EOF after the last one.
+	Next() (image.Image, error)
+	Close() error
+}
+
+// videoDecoders are the available decoders, by name. Each opens a video
+// file, producing frames at the given rate.
+var videoDecoders = map[string]func(path string, fps float64) (VideoDecoder, error){
+	"ffmpeg": newFFmpegDecoder,
+}
+
+// ffmpegDecoder decodes video by running ffmpeg, which must be on the PATH.
+type ffmpegDecoder struct {
+	cmd    *exec.Cmd
+	stdout io.ReadCloser
+	buf    []byte
+}
+
+func newFFmpegDecoder(path string, fps float64) (VideoDecoder, error) {
+	filter := fmt.Sprintf("fps=%g,scale=%d:%d", fps, videoFrameSize, videoFrameSize)
+	cmd := exec.Command("ffmpeg", "-loglevel", "error", "-i", path, "-vf", filter, "-f", "rawvideo", "-pix_fmt", "rgb24", "-")
+	cmd.Stderr = os.Stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	err = cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	d := &ffmpegDecoder{
+		cmd:    cmd,
+		stdout: stdout,
+		buf:    make([]byte, videoFrameSize*videoFrameSize*3),
+	}
+	return d, nil
+}
+
+func (d *ffmpegDecoder) Next() (image.Image, error) {
+	_, err := io.ReadFull(d.stdout, d.buf)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, videoFrameSize, videoFrameSize))
+	for i := 0; i < videoFrameSize*videoFrameSize; i++ {
+		copy(img.Pix[i*4:i*4+3], d.buf[i*3:i*3+3])
+		img.Pix[i*4+3] = 255
+	}
+	return img, nil
+}
+
+func (d *ffmpegDecoder) Close() error {
+	d.cmd.Process.Kill()
+	d.cmd.Wait()
+	return nil
+}
+
+func doVideoCommand(client Client, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf video <file> [--fps <fps>] [--loop] [--decoder <decoder>]")
+	}
+
+	fs := flag.NewFlagSet("video", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	fps := fs.Float64("fps", 15, "Frames per second to stream")
+	loop := fs.Bool("loop", false, "Play the video until interrupted")
+	decoderName := fs.String("decoder", "ffmpeg", "Video decoder ("+strings.Join(videoDecoderNames(), ", ")+")")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 || *fps <= 0 {
+		return usage()
+	}
+
+	newDecoder, ok := videoDecoders[*decoderName]
+	if !ok {
+		return fmt.Errorf("unknown decoder: %v", *decoderName)
+	}
+	path := positional[0]
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	polygons := layoutPolygons(panelInfo)
+	if len(polygons) == 0 {
+		return errors.New("no panels in layout")
+	}
+
+	decoder, err := newDecoder(path, *fps)
+	if err != nil {
+		return fmt.Errorf("failed to start decoder: %w", err)
+	}
+	defer func() { decoder.Close() }()
+
+	interval := time.Duration(float64(time.Second) / *fps)
+	frames := 0
+	return streamFrames(client, func() ([]SetPanelColor, time.Duration, bool, error) {
+		img, err := decoder.Next()
+		if errors.Is(err, io.EOF) && *loop && frames > 0 {
+			decoder.Close()
+			decoder, err = newDecoder(path, *fps)
+			if err != nil {
+				return nil, 0, false, err
+			}
+			frames = 0
+			img, err = decoder.Next()
+		}
+		if errors.Is(err, io.EOF) {
+			return nil, 0, false, nil
+		}
+		if err != nil {
+			return nil, 0, false, err
+		}
+
+		frames++
+		colors := samplePanelAverages(img, polygons)
+		for id, c := range colors {
+			colors[id] = client.calibrated(c)
+		}
+		return panelColorFrame(colors, interval), interval, true, nil
+	})
+}
+
+// videoDecoderNames returns the names of the available video decoders.
+func videoDecoderNames() []string {
+	var names []string
+	for name := range videoDecoders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"time"
+)
+
+// sunriseColors are the colors a wake-up sunrise passes through, from deep
+// red to warm white. They take up the first sunriseColorPhase of the ramp;
+// the rest raises the color temperature to daylight.
+var sunriseColors = []RGB{
+	{255, 0, 0},
+	{255, 60, 0},
+	{255, 140, 40},
+	{255, 190, 110},
+}
+
+const (
+	sunriseColorPhase      = 0.7
+	sunrisePhaseBrightness = 60
+)
+
+// sunriseDaylight is where a wake-up sunrise ends.
+var sunriseDaylight = lightLevel{6500, 100}
+
+// sunriseWarm is where the color temperature part of a sunrise starts.
+var sunriseWarm = lightLevel{2700, sunrisePhaseBrightness}
+
+func doWakeCommand(client Client, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf wake --at <HH:MM> [--over <duration>] [--weekdays]")
+	}
+
+	fs := flag.NewFlagSet("wake", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	at := fs.String("at", "", "Time of day to finish the sunrise")
+	over := fs.Duration("over", 20*time.Minute, "How long the sunrise takes")
+	weekdays := fs.Bool("weekdays", false, "Repeat every Monday to Friday")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 || *at == "" || *over <= 0 {
+		return usage()
+	}
+	alarm, err := time.Parse("15:04", *at)
+	if err != nil {
+		return fmt.Errorf("expected time as HH:MM, got %v", *at)
+	}
+
+	// The ramp does its own fading.
+	client.Duration = 0
+
+	for {
+		end := nextAlarm(time.Now(), alarm.Hour(), alarm.Minute(), *weekdays)
+		start := end.Add(-*over)
+		if client.Verbose {
+			fmt.Println("sunrise from", start.Format("Mon 15:04"), "to", end.Format("Mon 15:04"))
+		}
+		time.Sleep(time.Until(start))
+
+		err := sunrise(client, start, *over)
+		if err != nil {
+			return fmt.Errorf("failed to set sunrise color: %w", err)
+		}
+
+		if !*weekdays {
+			return nil
+		}
+	}
+}
+
+// nextAlarm returns the next time after now at the given hour and minute,
+// skipping weekends if weekdays is set.
+func nextAlarm(now time.Time, hour, minute int, weekdays bool) time.Time {
+	alarm := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	for !alarm.After(now) || (weekdays && (alarm.Weekday() == time.Saturday || alarm.Weekday() == time.Sunday)) {
+		alarm = alarm.AddDate(0, 0, 1)
+	}
+	return alarm
+}
+
+// sunrise ramps the Nanoleaf from deep red to daylight over the given
+// duration from start. If start has passed, it joins the ramp partway.
+func sunrise(client Client, start time.Time, duration time.Duration) error {
+	step := duration / 200
+	if step < time.Second {
+		step = time.Second
+	}
+
+	err := client.On()
+	if err != nil {
+		return err
+	}
+
+	for {
+		f := float64(time.Since(start)) / float64(duration)
+		if f > 1 {
+			f = 1
+		}
+
+		if f < sunriseColorPhase {
+			t := f / sunriseColorPhase
+			c := sunriseColor(t)
+			h, s, _ := rgbToHSV(int(c.Red), int(c.Green), int(c.Blue))
+			brightness := 1 + int(math.Round(t*(sunrisePhaseBrightness-1)))
+			err = client.SetHSV(h, s, brightness)
+		} else {
+			t := (f - sunriseColorPhase) / (1 - sunriseColorPhase)
+			level := sunriseWarm.lerp(sunriseDaylight, t)
+			err = client.SetColorTemperature(level.Kelvin)
+			if err == nil {
+				err = client.SetBrightness(level.Brightness)
+			}
+		}
+		if err != nil || f == 1 {
+			return err
+		}
+		time.Sleep(step)
+	}
+}
+
+// sunriseColor returns the color t of the way through sunriseColors.
+func sunriseColor(t float64) RGB {
+	n := len(sunriseColors) - 1
+	i := int(t * float64(n))
+	if i >= n {
+		return sunriseColors[n]
+	}
+	return blend(sunriseColors[i], sunriseColors[i+1], t*float64(n)-float64(i))
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// WatchChange is a change to the Nanoleaf state seen by `picoleaf watch`.
+type WatchChange struct {
+	Time     time.Time   `json:"time"`
+	Property string      `json:"property"`
+	From     interface{} `json:"from"`
+	To       interface{} `json:"to"`
+}
+
+// watchedProperties are the state properties `picoleaf watch` reports, in
+// the order changes are printed.
+var watchedProperties = []struct {
+	Name  string
+	Value func(*PanelInfo) interface{}
+}{
+	{"on", func(p *PanelInfo) interface{} { return p.State.On.Value }},
+	{"brightness", func(p *PanelInfo) interface{} { return p.State.Brightness.Value }},
+	{"colorMode", func(p *PanelInfo) interface{} { return p.State.ColorMode }},
+	{"hue", func(p *PanelInfo) interface{} { return p.State.Hue.Value }},
+	{"sat", func(p *PanelInfo) interface{} { return p.State.Saturation.Value }},
+	{"ct", func(p *PanelInfo) interface{} { return p.State.ColorTemperature.Value }},
+	{"effect", func(p *PanelInfo) interface{} { return p.Effects.Selected }},
+}
+
+func doWatchCommand(client Client, args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	interval := fs.Duration("interval", 2*time.Second, "How often to check the state")
+	asJSON := fs.Bool("json", false, "Print changes as JSON, one object per line")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 || *interval <= 0 {
+		return usageError("usage: picoleaf watch [--interval <duration>] [--json]")
+	}
+
+	previous, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	for {
+		time.Sleep(*interval)
+		panelInfo, err := client.GetPanelInfo()
+		if err != nil {
+			fmt.Println("error: failed to get Nanoleaf state:", err)
+			continue
+		}
+
+		now := time.Now()
+		for _, property := range watchedProperties {
+			from, to := property.Value(previous), property.Value(panelInfo)
+			if from == to {
+				continue
+			}
+
+			if *asJSON {
+				encoder.Encode(WatchChange{Time: now, Property: property.Name, From: from, To: to})
+			} else {
+				fmt.Printf("%s  %s: %v → %v\n", now.Format("15:04:05"), property.Name, from, to)
+			}
+		}
+		previous = panelInfo
+	}
+}
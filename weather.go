@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Weather describes current conditions at a location.
+type Weather struct {
+	Condition   string
+	Day         bool
+	Temperature float64
+}
+
+// Weather conditions reported by providers.
+const (
+	WeatherClear  = "clear"
+	WeatherCloudy = "cloudy"
+	WeatherFog    = "fog"
+	WeatherRain   = "rain"
+	WeatherSnow   = "snow"
+	WeatherStorm  = "storm"
+)
+
+// weatherProviders fetch the current weather for a named location.
+var weatherProviders = map[string]func(client http.Client, location string) (Weather, error){
+	"open-meteo": openMeteoWeather,
+}
+
+// weatherLook is how a weather condition is shown: a color, and whether
+// it pulses.
+type weatherLook struct {
+	Color RGB
+	Pulse bool
+}
+
+// weatherLooks maps conditions to how they're shown by day. Clear nights
+// are shown separately.
+var weatherLooks = map[string]weatherLook{
+	WeatherClear:  {RGB{255, 170, 60}, false},
+	WeatherCloudy: {RGB{170, 180, 200}, false},
+	WeatherFog:    {RGB{120, 120, 130}, true},
+	WeatherRain:   {RGB{30, 90, 255}, true},
+	WeatherSnow:   {RGB{230, 240, 255}, true},
+	WeatherStorm:  {RGB{140, 40, 255}, true},
+}
+
+// clearNightLook is how clear weather is shown at night.
+var clearNightLook = weatherLook{RGB{20, 30, 120}, false}
+
+// weatherPulsePeriod is how long one pulse of a pulsing condition takes.
+const weatherPulsePeriod = 4 * time.Second
+
+func doWeatherCommand(client Client, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf weather --location <place> [--provider <provider>] [--every <duration>]")
+	}
+
+	fs := flag.NewFlagSet("weather", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	location := fs.String("location", "", "Place to show the weather for, e.g. Berlin")
+	providerName := fs.String("provider", "open-meteo", "Weather provider ("+strings.Join(weatherProviderNames(), ", ")+")")
+	every := fs.Duration("every", 15*time.Minute, "How often to refresh, or 0 to show the weather once")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 || *location == "" || *every < 0 {
+		return usage()
+	}
+
+	provider, ok := weatherProviders[*providerName]
+	if !ok {
+		return fmt.Errorf("unknown weather provider: %v", *providerName)
+	}
+
+	httpClient := http.Client{Timeout: 10 * time.Second}
+	panelInfo, err := getPanelInfo(client)
+	if err != nil {
+		return err
+	}
+	ids := panelIDs(panelInfo)
+
+	for {
+		weather, err := provider(httpClient, *location)
+		if err != nil {
+			err = fmt.Errorf("failed to get weather: %w", err)
+		} else {
+			if client.Verbose {
+				fmt.Printf("%s, %.1f°C\n", weather.Condition, weather.Temperature)
+			}
+			err = showWeather(client, ids, weather)
+			if err != nil {
+				err = fmt.Errorf("failed to display effect: %w", err)
+			}
+		}
+
+		if *every == 0 {
+			return err
+		}
+		if err != nil {
+			fmt.Println("error:", err)
+		}
+		time.Sleep(*every)
+	}
+}
+
+// showWeather displays an effect for the weather on the given panels.
+func showWeather(client Client, ids []uint16, weather Weather) error {
+	look, ok := weatherLooks[weather.Condition]
+	if !ok {
+		look = weatherLooks[WeatherCloudy]
+	}
+	if weather.Condition == WeatherClear && !weather.Day {
+		look = clearNightLook
+	}
+
+	c := client.calibrated(look.Color)
+	depth := 0.0
+	if look.Pulse {
+		depth = 0.6
+	}
+	return client.DisplayEffect(Effect{
+		Type:     "custom",
+		AnimData: EncodeAnimData(breatheAnimation(ids, c, weatherPulsePeriod, depth)),
+		Loop:     true,
+	})
+}
+
+// openMeteoWeather fetches the current weather from Open-Meteo, which needs
+// no API key.
+func openMeteoWeather(client http.Client, location string) (Weather, error) {
+	var places struct {
+		Results []struct {
+			Latitude  float64 `json:"latitude"`
+			Longitude float64 `json:"longitude"`
+		} `json:"results"`
+	}
+	err := getJSON(client, "https://geocoding-api.open-meteo.com/v1/search?count=1&name="+url.QueryEscape(location), &places)
+	if err != nil {
+		return Weather{}, err
+	}
+	if len(places.Results) == 0 {
+		return Weather{}, fmt.Errorf("unknown location %q", location)
+	}
+
+	var forecast struct {
+		Current struct {
+			Temperature float64 `json:"temperature"`
+			WeatherCode int     `json:"weathercode"`
+			IsDay       int     `json:"is_day"`
+		} `json:"current_weather"`
+	}
+	place := places.Results[0]
+	forecastURL := fmt.Sprintf("https://api.open-meteo.com/v1/forecast?current_weather=true&latitude=%f&longitude=%f", place.Latitude, place.Longitude)
+	err = getJSON(client, forecastURL, &forecast)
+	if err != nil {
+		return Weather{}, err
+	}
+
+	return Weather{
+		Condition:   wmoCondition(forecast.Current.WeatherCode),
+		Day:         forecast.Current.IsDay == 1,
+		Temperature: forecast.Current.Temperature,
+	}, nil
+}
+
+// wmoCondition maps a WMO weather interpretation code to a condition.
+func wmoCondition(code int) string {
+	switch {
+	case code == 0 || code == 1:
+		return WeatherClear
+	case code == 2 || code == 3:
+		return WeatherCloudy
+	case code == 45 || code == 48:
+		return WeatherFog
+	case code >= 51 && code <= 67, code >= 80 && code <= 82:
+		return WeatherRain
+	case code >= 71 && code <= 77, code == 85 || code == 86:
+		return WeatherSnow
+	case code >= 95:
+		return WeatherStorm
+	default:
+		return WeatherCloudy
+	}
+}
+
+// getJSON fetches a URL and decodes its JSON response into v.
+func getJSON(client http.Client, url string, v interface{}) error {
+	res, err := client.Get(url)
+	if err != nil {
+		return &NetworkError{Op: "GET " + url, Err: err}
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// weatherProviderNames returns the names of the available providers.
+func weatherProviderNames() []string {
+	var names []string
+	for name := range weatherProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// webhookMappings collects repeated --map flags.
+type webhookMappings []string
+
+func (m *webhookMappings) String() string {
+	return strings.Join(*m, ", ")
+}
+
+func (m *webhookMappings) Set(value string) error {
+	*m = append(*m, value)
+	return nil
+}
+
+func doWebhookCommand(client Client, cfg *ini.File, args []string) error {
+	usage := func() error {
+		return usageError("usage: picoleaf webhook [--listen <address>] [--token <token>] --map <path>=<action>[:<argument>] ...")
+	}
+
+	var mappings webhookMappings
+	fs := flag.NewFlagSet("webhook", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Println(usage()) }
+	listen := fs.String("listen", ":8080", "Address to listen on")
+	token := fs.String("token", cfg.Section("webhook").Key("token").String(), "Token callers must send")
+	fs.Var(&mappings, "map", "Path and the action it runs, like /doorbell=notify:red")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 || len(mappings) == 0 {
+		return usage()
+	}
+	if *token == "" {
+		return errors.New("no token set, pass --token or set token in the [webhook] section")
+	}
+
+	actions := map[string]gestureAction{}
+	for _, mapping := range mappings {
+		i := strings.Index(mapping, "=")
+		if i < 0 || !strings.HasPrefix(mapping, "/") {
+			return fmt.Errorf("expected --map <path>=<action>, got %v", mapping)
+		}
+		path, spec := mapping[:i], strings.Replace(mapping[i+1:], ":", " ", 1)
+		action, err := parseRuleAction(cfg, spec)
+		if err != nil {
+			return fmt.Errorf("invalid action for %s: %w", path, err)
+		}
+		actions[path] = action
+	}
+
+	// Actions run one at a time, so overlapping webhooks can't interleave
+	// their changes.
+	var mu sync.Mutex
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !webhookAuthorized(r, *token) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		action, ok := actions[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Printf("%s %s\n", time.Now().Format("2006-01-02 15:04:05"), r.URL.Path)
+		client := client
+		client.Context = r.Context()
+		err := action(client)
+		if err != nil {
+			fmt.Printf("error: %s: %v\n", r.URL.Path, err)
+			http.Error(w, publicError(err), http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	err := http.ListenAndServe(*listen, traceHandler(handler))
+	return fmt.Errorf("failed to listen for webhooks: %w", err)
+}
+
+// webhookAuthorized reports whether a request carries the token, either as
+// a bearer token or in the token query parameter, for services that can't
+// set headers.
+func webhookAuthorized(r *http.Request, token string) bool {
+	given := r.URL.Query().Get("token")
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		given = strings.TrimPrefix(auth, "Bearer ")
+	}
+	return subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
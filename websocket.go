@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+	"gopkg.in/ini.v1"
+)
+
+// ServeEvent is a message pushed to WebSocket clients of `picoleaf serve`:
+// a change reported by a device's event stream, or the result of a control
+// message.
+type ServeEvent struct {
+	Type      string      `json:"type"`
+	Device    string      `json:"device,omitempty"`
+	Attribute string      `json:"attribute,omitempty"`
+	Value     interface{} `json:"value,omitempty"`
+	PanelID   int         `json:"panelId,omitempty"`
+	Gesture   string      `json:"gesture,omitempty"`
+
+	// ID and Error are set on results, with the ID of the control message.
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// ServeControl is a message from a WebSocket client asking for an action,
+// like "brightness +10", on a device. Actions are the same as for rules,
+// except `url`.
+type ServeControl struct {
+	ID     string `json:"id"`
+	Device string `json:"device"`
+	Action string `json:"action"`
+}
+
+// serveEventBuffer is how many events may wait for a slow WebSocket client
+// before more are dropped.
+const serveEventBuffer = 64
+
+// eventHub fans out the events of every device to WebSocket clients. Each
+// device's event stream is opened when the first client connects.
+type eventHub struct {
+	mu      sync.Mutex
+	started bool
+	clients map[chan ServeEvent]bool
+}
+
+// subscribe returns a channel receiving every device's events, starting
+// the event streams if needed.
+func (h *eventHub) subscribe(devices map[string]Client) chan ServeEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.started {
+		h.started = true
+		for name, client := range devices {
+			go h.listen(name, client)
+		}
+	}
+	if h.clients == nil {
+		h.clients = map[chan ServeEvent]bool{}
+	}
+	events := make(chan ServeEvent, serveEventBuffer)
+	h.clients[events] = true
+	return events
+}
+
+func (h *eventHub) unsubscribe(events chan ServeEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.clients, events)
+}
+
+func (h *eventHub) broadcast(event ServeEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for events := range h.clients {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
+
+// listen broadcasts a device's events, reconnecting whenever its event
+// stream drops.
+func (h *eventHub) listen(name string, client Client) {
+	types := []EventType{EventState, EventLayout, EventEffects, EventTouch}
+	for {
+		err := client.SubscribeEvents(types, func(event Event) error {
+			for _, item := range event.Items {
+				if event.Type == EventTouch {
+					h.broadcast(ServeEvent{Type: "touch", Device: name, PanelID: item.PanelID, Gesture: item.Gesture.String()})
+					continue
+				}
+				var value interface{}
+				json.Unmarshal(item.Value, &value)
+				h.broadcast(ServeEvent{Type: event.Type.String(), Device: name, Attribute: event.AttrName(item), Value: value})
+			}
+			return nil
+		})
+		fmt.Printf("error: lost connection to %s events: %v\n", name, err)
+		time.Sleep(eventReconnectDelay)
+	}
+}
+
+// serveWebSocket pushes events to a WebSocket client and runs the control
+// messages it sends, replying to each with a result.
+func (s *server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	// ServeHTTP has already checked the origin, like for every route.
+	ws := websocket.Server{
+		Handler: func(conn *websocket.Conn) {
+			events := s.hub.subscribe(s.devices)
+			defer s.hub.unsubscribe(events)
+
+			// Results are sent through events too, so only one goroutine
+			// writes to the connection.
+			done := make(chan struct{})
+			quit := make(chan struct{})
+			defer close(quit)
+			go func() {
+				defer close(done)
+				for {
+					var control ServeControl
+					err := websocket.JSON.Receive(conn, &control)
+					if err != nil {
+						return
+					}
+					result := ServeEvent{Type: "result", Device: control.Device, ID: control.ID}
+					if err := s.control(conn.Request().Context(), control); err != nil {
+						result.Error = publicError(err)
+					}
+					select {
+					case events <- result:
+					case <-quit:
+						return
+					}
+				}
+			}()
+
+			for {
+				select {
+				case event := <-events:
+					err := websocket.JSON.Send(conn, event)
+					if err != nil {
+						return
+					}
+				case <-done:
+					return
+				}
+			}
+		},
+	}
+	ws.ServeHTTP(w, r)
+}
+
+// parseControlAction parses a control message's action. Actions are the
+// same as for rules, except `url`, which would let callers make the server
+// send requests anywhere.
+func parseControlAction(cfg *ini.File, spec string) (gestureAction, error) {
+	fields := strings.Fields(spec)
+	if len(fields) > 0 && fields[0] == "url" {
+		return nil, validationErrorf("url actions aren't allowed in control messages")
+	}
+	return parseRuleAction(cfg, spec)
+}
+
+// control runs a control message's action, in a span that's a child of ctx.
+func (s *server) control(ctx context.Context, control ServeControl) (err error) {
+	ctx, span := startSpan(ctx, "control "+control.Action)
+	defer func() { endSpan(span, err) }()
+
+	client, ok := s.devices[control.Device]
+	if !ok {
+		return fmt.Errorf("unknown device: %s", control.Device)
+	}
+	if control.Action == "" {
+		return errors.New("no action")
+	}
+	action, err := parseControlAction(s.cfg, control.Action)
+	if err != nil {
+		return err
+	}
+	client.Context = ctx
+	return action(client)
+}